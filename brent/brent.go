@@ -0,0 +1,247 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package brent provides means of detecting cycles, using Brent's algorithm, with an API that mirrors the Detector
+// from the floyds package, so that the two may be used interchangeably.
+package brent
+
+import (
+	"errors"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+)
+
+/*
+The Detector struct is a cycle detector using Brent's algorithm, which, like floyds.Detector, uses a tortoise and a
+hare, but the tortoise never steps on it's own, instead it teleports to the hare's position, every time the distance
+between them reaches a power of two. This means that next is never called while detecting a cycle using the `Hare`
+method, and only once per step using the `Next` method, which is a significant saving when next is expensive, compared
+to the roughly three calls per tortoise step that Floyd's algorithm requires. As a bonus, the length of the cycle
+(lambda) is known as soon as the cycle is detected.
+
+Like floyds.Detector, this implementation treats the current state as immutable, and the memory cost of the Detector
+struct itself is O(1). If you call any methods on something that was not constructed using the constructor, a panic
+will occur.
+
+Usage:
+
+	- Create with `NewDetector`, providing start and next, optionally providing compare (defaults to identity).
+	- Increment with either `Hare` OR `Next`, using `Ok` check for cycles, and passing down the new structs.
+	- If you are using `Next` method, you will want to indicate done (out of bounds), by make it return false
+		from the next method, or you may not get the results you expect.
+	- Once `Ok` returns false, call `Result` to find where the cycle starts (mu), and how long it is (lambda).
+
+Brent's algorithm, for reference. The first segment is implemented by `Hare` and `Next`, and the remaining segment is
+implemented by `Result`, which uses next and the start value.
+
+https://en.wikipedia.org/wiki/Cycle_detection
+
+	def brent(f, x0):
+		# main phase: search successive powers of two
+		power = lam = 1
+		tortoise = x0
+		hare = f(x0)  # f(x0) is the element/node next to x0.
+		while tortoise != hare:
+			if power == lam:  # time to start a new power of two?
+				tortoise = hare
+				power *= 2
+				lam = 0
+			hare = f(hare)
+			lam += 1
+
+		# Find the position of the first repetition of length λ
+		tortoise = hare = x0
+		for i in range(lam):
+		# range(lam) produces a list with the values 0, 1, ... , lam-1
+			hare = f(hare)
+		# The distance between the hare and tortoise is now λ.
+
+		# Next, the hare and tortoise move at same speed until they agree
+		mu = 0
+		while tortoise != hare:
+			tortoise = f(tortoise)
+			hare = f(hare)
+			mu += 1
+
+		return lam, mu
+*/
+type Detector struct {
+	next          func(v interface{}) (step interface{}, ok bool)
+	compare       func(tortoise, hare interface{}) bool
+	start         interface{}
+	tortoise      interface{}
+	hare          interface{}
+	ok            bool
+	done          bool
+	hareCount     int
+	tortoiseCount int
+	power         int
+	lambda        int
+}
+
+// NewDetector constructs a new Detector struct, and must provide the start step, and function to resolve the next
+// step (from the previous step each time), and may optionally include a custom comparison method, which defaults to
+// floyds.CompareIdentity.
+func NewDetector(start interface{}, next func(v interface{}) (interface{}, bool), compare func(tortoise, hare interface{}) bool) Detector {
+	if nil == next {
+		panic(errors.New("[NewDetector] next must be non-nil"))
+	}
+	if nil == compare {
+		compare = floyds.CompareIdentity
+	}
+	return Detector{next, compare, start, start, start, true, false, 0, 0, 1, 0}
+}
+
+func (f Detector) validate() {
+	if nil == f.next || nil == f.compare {
+		panic(errors.New("[Detector.validate] nil property encountered, use the constructor NewDetector"))
+	}
+}
+
+// Ok will return true only if there has been no cycle detected so far.
+func (f Detector) Ok() bool {
+	f.validate()
+	return f.ok
+}
+
+// SetNext returns a new Detector that is the same as the receiver, but with the provided next function.
+func (f Detector) SetNext(next func(v interface{}) (step interface{}, ok bool)) Detector {
+	f.validate()
+	if nil == next {
+		panic(errors.New("[Detector.SetNext] you cannot set a nil next"))
+	}
+	f.next = next
+	return f
+}
+
+// SetCompare returns a new Detector that is the same as the receiver, but with the provided compare function.
+func (f Detector) SetCompare(compare func(tortoise, hare interface{}) bool) Detector {
+	f.validate()
+	if nil == compare {
+		panic(errors.New("[Detector.SetCompare] you cannot set a nil compare"))
+	}
+	f.compare = compare
+	return f
+}
+
+// Hare returns a new Detector with the hare moved forward one step (which must be provided), teleporting the
+// tortoise to the hare's previous position first, if the distance between them had reached the current power of two.
+// Unlike floyds.Detector, next is never called.
+func (f Detector) Hare(step interface{}) Detector {
+	f.validate()
+	if false == f.ok || true == f.done {
+		return f
+	}
+
+	// time to start a new power of two
+	if f.power == f.lambda {
+		f.tortoise = f.hare
+		f.tortoiseCount = f.hareCount
+		f.power *= 2
+		f.lambda = 0
+	}
+
+	f.hare = step
+	f.hareCount++
+	f.lambda++
+
+	f.ok = false == f.compare(f.tortoise, f.hare)
+	return f
+}
+
+// Next returns a new Detector with the hare moved forward one step, using next, which is otherwise the same as
+// calling `Hare` with the step.
+func (f Detector) Next() Detector {
+	f.validate()
+	if false == f.ok || true == f.done {
+		return f
+	}
+	step, ok := f.next(f.hare)
+	if false == ok {
+		// no change, exit immediately - there was no cycle
+		f.done = true
+		return f
+	}
+	return f.Hare(step)
+}
+
+// HareCount gets the number of steps that hare has taken, since the start.
+func (f Detector) HareCount() int {
+	f.validate()
+	return f.hareCount
+}
+
+// TortoiseCount gets the number of steps from the start to the tortoise's current position, which, since the tortoise
+// only ever teleports to the hare, will always be zero, or a power of two minus one.
+func (f Detector) TortoiseCount() int {
+	f.validate()
+	return f.tortoiseCount
+}
+
+// Done will return true if any calls to next have returned a false ok value.
+func (f Detector) Done() bool {
+	f.validate()
+	return f.done
+}
+
+// Result models the location and length of a cycle, as found by Detector.Result.
+type Result struct {
+	// Mu is the index of the first step that is part of the cycle, where the start step has index 0.
+	Mu int
+	// Lambda is the length of the cycle, also known as the period.
+	Lambda int
+	// Entry is the step at index Mu, the first step that is part of the cycle.
+	Entry interface{}
+	// Meeting is the step the hare was on, when it caught up to the tortoise.
+	Meeting interface{}
+}
+
+// Result runs the remaining segment of Brent's algorithm, to find the start (mu) of the cycle, using next and the
+// start value, the length (lambda) being known already. The ok return value will be false if no cycle has been
+// detected yet, or if next returned false while stepping, which should only be possible if next is not consistent
+// with the steps that were passed to `Hare`. Note that next will be called up to mu + lambda times for the hare, and
+// mu times for the tortoise, and, if the steps taken were not actually generated by next, it may never return.
+func (f Detector) Result() (result Result, ok bool) {
+	f.validate()
+	if true == f.ok {
+		return Result{}, false
+	}
+
+	result.Meeting = f.hare
+	result.Lambda = f.lambda
+
+	// the hare is placed lambda steps ahead of the tortoise
+	tortoise, hare := f.start, f.start
+	for x := 0; x < f.lambda; x++ {
+		if hare, ok = f.next(hare); false == ok {
+			return Result{}, false
+		}
+	}
+
+	// then the hare and the tortoise move at the same speed, until they agree, at mu
+	for false == f.compare(tortoise, hare) {
+		if tortoise, ok = f.next(tortoise); false == ok {
+			return Result{}, false
+		}
+		if hare, ok = f.next(hare); false == ok {
+			return Result{}, false
+		}
+		result.Mu++
+	}
+	result.Entry = tortoise
+
+	return result, true
+}
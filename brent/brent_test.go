@@ -0,0 +1,279 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package brent
+
+import (
+	"testing"
+)
+
+// rhoNext returns a next function for the sequence 0, 1, 2, ..., which returns to mu after mu + lambda - 1.
+func rhoNext(mu, lambda int) func(v interface{}) (interface{}, bool) {
+	return func(v interface{}) (interface{}, bool) {
+		n := v.(int) + 1
+		if n >= mu+lambda {
+			n = mu
+		}
+		return n, true
+	}
+}
+
+func expectPanic(t *testing.T, message string, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if err, ok := recover().(error); false == ok || nil == err || message != err.Error() {
+			t.Fatal(err)
+		}
+	}()
+	fn()
+	t.Fatal()
+}
+
+// sameState returns true if the non-func fields of a and b are equal.
+func sameState(a, b Detector) bool {
+	return a.start == b.start && a.tortoise == b.tortoise && a.hare == b.hare && a.ok == b.ok && a.done == b.done &&
+		a.hareCount == b.hareCount && a.tortoiseCount == b.tortoiseCount && a.power == b.power && a.lambda == b.lambda
+}
+
+func TestDetector_Hare(t *testing.T) {
+	for mu := 0; mu < 20; mu++ {
+		for lambda := 1; lambda < 20; lambda++ {
+			next := rhoNext(mu, lambda)
+			f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+				t.Fatal()
+				return nil, false
+			}, nil)
+			step := interface{}(0)
+			for x := 0; f.Ok(); x++ {
+				if x > 4*(mu+lambda) {
+					t.Fatal(mu, lambda)
+				}
+				if f.HareCount() != x {
+					t.Fatal(mu, lambda)
+				}
+				if tc := f.TortoiseCount(); 0 != tc && 0 != (tc+1)&tc {
+					t.Fatal(mu, lambda, tc)
+				}
+				step, _ = next(step)
+				f = f.Hare(step)
+				if f.hare != step {
+					t.Fatal(mu, lambda)
+				}
+			}
+			if f.lambda != lambda || true == f.Done() {
+				t.Fatal(mu, lambda, f.lambda)
+			}
+			f = f.SetNext(next)
+			result, ok := f.Result()
+			if false == ok || mu != result.Mu || lambda != result.Lambda || mu != result.Entry || f.hare != result.Meeting {
+				t.Fatal(mu, lambda, ok, result)
+			}
+		}
+	}
+}
+
+func TestDetector_Next(t *testing.T) {
+	for mu := 0; mu < 20; mu++ {
+		for lambda := 1; lambda < 20; lambda++ {
+			calls := 0
+			next := rhoNext(mu, lambda)
+			f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+				calls++
+				return next(v)
+			}, nil)
+			for f.Ok() {
+				f = f.Next()
+			}
+			if calls != f.HareCount() {
+				t.Fatal(mu, lambda, calls, f.HareCount())
+			}
+			result, ok := f.Result()
+			if false == ok || mu != result.Mu || lambda != result.Lambda || mu != result.Entry || f.hare != result.Meeting {
+				t.Fatal(mu, lambda, ok, result)
+			}
+		}
+	}
+}
+
+func TestDetector_Next_noCycle(t *testing.T) {
+	next := func(v interface{}) (interface{}, bool) {
+		if 10 == v {
+			return nil, false
+		}
+		return v.(int) + 1, true
+	}
+	f := NewDetector(0, next, nil)
+	for x := 0; x < 100 && false == f.Done(); x++ {
+		f = f.Next()
+	}
+	if false == f.Ok() || false == f.Done() || 10 != f.HareCount() || 10 != f.hare || 7 != f.TortoiseCount() {
+		t.Fatal(f)
+	}
+	if result, ok := f.Result(); true == ok || (Result{}) != result {
+		t.Fatal(result)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestDetector_compare(t *testing.T) {
+	list := []string{"a", "b", "c", "d", "b", "c", "d", "b", "c", "d", "b", "c", "d"}
+	next := func(v interface{}) (interface{}, bool) {
+		return v.(int) + 1, true
+	}
+	compare := func(a, b interface{}) bool {
+		return list[a.(int)] == list[b.(int)]
+	}
+	f := NewDetector(0, next, compare)
+	for f.Ok() {
+		f = f.Next()
+	}
+	result, ok := f.Result()
+	if false == ok || 1 != result.Mu || 3 != result.Lambda || 1 != result.Entry {
+		t.Fatal(ok, result)
+	}
+}
+
+func TestDetector_Result_nextNotOk(t *testing.T) {
+	for _, limit := range []int{0, 1, 3, 6, 8} {
+		count := 0
+		f := NewDetector(0, rhoNext(4, 5), nil)
+		for f.Ok() {
+			f = f.Next()
+		}
+		f = f.SetNext(func(v interface{}) (interface{}, bool) {
+			count++
+			if count > limit {
+				return nil, false
+			}
+			return rhoNext(4, 5)(v)
+		})
+		if result, ok := f.Result(); false != ok || (Result{}) != result {
+			t.Fatal(limit, ok, result)
+		}
+	}
+}
+
+func TestDetector_notOk(t *testing.T) {
+	f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+		t.Fatal()
+		return nil, false
+	}, func(tortoise, hare interface{}) bool {
+		t.Fatal()
+		return false
+	})
+	f.ok = false
+	if f2 := f.Hare(1); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestDetector_done(t *testing.T) {
+	f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+		t.Fatal()
+		return nil, false
+	}, func(tortoise, hare interface{}) bool {
+		t.Fatal()
+		return false
+	})
+	f.done = true
+	if f2 := f.Hare(1); false == sameState(f, f2) || false == f2.Done() {
+		t.Fatal(f2)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	next := func(v interface{}) (interface{}, bool) {
+		return "next", true
+	}
+	f := NewDetector(23, next, nil)
+	if nil == f.next || nil == f.compare || 23 != f.start || 23 != f.tortoise || 23 != f.hare ||
+		false == f.ok || true == f.done || 0 != f.hareCount || 0 != f.tortoiseCount || 1 != f.power || 0 != f.lambda {
+		t.Fatal(f)
+	}
+	if false == f.compare(2323, 2323) || true == f.compare(2323, 2324) || true == f.compare("1", 1) {
+		t.Fatal()
+	}
+	f = NewDetector(23, next, func(a, b interface{}) bool {
+		return a == "left" && b == "right"
+	})
+	if true == f.compare("right", "left") || false == f.compare("left", "right") {
+		t.Fatal()
+	}
+}
+
+func TestNewDetector_panic(t *testing.T) {
+	expectPanic(t, "[NewDetector] next must be non-nil", func() {
+		NewDetector(nil, nil, nil)
+	})
+}
+
+func TestDetector_SetNext(t *testing.T) {
+	f := NewDetector(1, rhoNext(0, 1), nil)
+	f = f.SetNext(func(a interface{}) (interface{}, bool) {
+		if 12 != a {
+			t.Fatal()
+		}
+		return "aa", true
+	})
+	if n, ok := f.next(12); false == ok || "aa" != n {
+		t.Fatal()
+	}
+	expectPanic(t, "[Detector.SetNext] you cannot set a nil next", func() {
+		f.SetNext(nil)
+	})
+}
+
+func TestDetector_SetCompare(t *testing.T) {
+	f := NewDetector(1, rhoNext(0, 1), nil)
+	f = f.SetCompare(func(a, b interface{}) bool {
+		if 1 != a || 2 != b {
+			t.Fatal()
+		}
+		return true
+	})
+	if true != f.compare(1, 2) {
+		t.Fatal()
+	}
+	expectPanic(t, "[Detector.SetCompare] you cannot set a nil compare", func() {
+		f.SetCompare(nil)
+	})
+}
+
+func TestDetector_validate_panic(t *testing.T) {
+	const message = "[Detector.validate] nil property encountered, use the constructor NewDetector"
+	for _, fn := range []func(f Detector){
+		func(f Detector) { f.Ok() },
+		func(f Detector) { f.Done() },
+		func(f Detector) { f.HareCount() },
+		func(f Detector) { f.TortoiseCount() },
+		func(f Detector) { f.Hare(nil) },
+		func(f Detector) { f.Next() },
+		func(f Detector) { f.Result() },
+		func(f Detector) { f.SetNext(nil) },
+		func(f Detector) { f.SetCompare(nil) },
+	} {
+		expectPanic(t, message, func() { fn(Detector{}) })
+	}
+}
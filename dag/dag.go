@@ -0,0 +1,247 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package dag provides a directed acyclic graph, that prevents cycles as edges are added, one at a time, while
+// maintaining a topological order, using the online algorithm of Pearce and Kelly, which only visits the nodes
+// between the two ends of the edge, in the current order, and only when the edge contradicts that order, rather than
+// searching the whole graph, each time.
+package dag
+
+import (
+	"sort"
+
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+type (
+	// DAG is a directed acyclic graph, and a graph.Graph, with the nodes returned by Nodes in the order they were
+	// first added, and the successors of each node in the order the edges were added. The zero value is an empty
+	// graph, ready to use, note that it's not safe to use concurrently, while it's modified.
+	DAG struct {
+		nodes        []interface{}
+		index        map[interface{}]int
+		successors   [][]int
+		predecessors [][]int
+		edges        map[[2]int]struct{}
+		// ord is the position of each node, in the topological order, and position is the inverse
+		ord      []int
+		position []int
+		visited  []bool
+	}
+
+	// CycleError is returned by DAG.AddEdge, in place of an edge that would close a cycle, which was therefore not
+	// added, meaning the cycle is made up of the rejected edge, and a path of edges already in the graph.
+	CycleError struct {
+		// Cycle contains the nodes that would form the cycle, formatted like floyds.BranchingDetector.Cycle, starting
+		// and ending with the node the edge would be from, followed by the node it would be to, e.g. `[a b c a]`,
+		// for an edge from a to b.
+		Cycle []interface{}
+	}
+)
+
+// Error implements the error interface, describing the cycle, formatting each node using `%v`.
+func (e *CycleError) Error() string {
+	return digraph.FormatCycle("cycle detected: ", " -> ", e.Cycle)
+}
+
+// AddNode adds node to the graph, at the end of the topological order, if it isn't already present.
+func (g *DAG) AddNode(node interface{}) {
+	g.add(node)
+}
+
+// The add method returns the index of node, adding it if it isn't already present.
+func (g *DAG) add(node interface{}) int {
+	if nil == g.index {
+		g.index = make(map[interface{}]int)
+		g.edges = make(map[[2]int]struct{})
+	}
+	if i, ok := g.index[node]; true == ok {
+		return i
+	}
+	i := len(g.nodes)
+	g.nodes = append(g.nodes, node)
+	g.index[node] = i
+	g.successors = append(g.successors, nil)
+	g.predecessors = append(g.predecessors, nil)
+	g.ord = append(g.ord, i)
+	g.position = append(g.position, i)
+	g.visited = append(g.visited, false)
+	return i
+}
+
+// AddEdge adds an edge from one node to another, adding either node, if it isn't already present, unless doing so
+// would create a cycle, in which case the graph is not modified (other than adding any nodes), and a *CycleError is
+// returned, describing the cycle. Adding an edge that is already present does nothing. The topological order is
+// only modified if the edge contradicts it, in which case only the nodes between the two, in the current order, are
+// visited, and possibly reordered.
+func (g *DAG) AddEdge(from, to interface{}) error {
+	x, y := g.add(from), g.add(to)
+	if _, ok := g.edges[[2]int{x, y}]; true == ok {
+		return nil
+	}
+	if x == y {
+		return &CycleError{Cycle: []interface{}{from, from}}
+	}
+	if lb, ub := g.ord[y], g.ord[x]; lb < ub {
+		forward, path := g.search(y, g.successors, func(v int) bool { return g.ord[v] <= ub }, x)
+		if nil != path {
+			cycle := make([]interface{}, 0, len(path)+1)
+			cycle = append(cycle, from)
+			for _, v := range path {
+				cycle = append(cycle, g.nodes[v])
+			}
+			return &CycleError{Cycle: cycle}
+		}
+		backward, _ := g.search(x, g.predecessors, func(v int) bool { return lb < g.ord[v] }, -1)
+		g.reorder(backward, forward)
+	}
+	g.edges[[2]int{x, y}] = struct{}{}
+	g.successors[x] = append(g.successors[x], y)
+	g.predecessors[y] = append(g.predecessors[y], x)
+	return nil
+}
+
+// RemoveEdge removes the edge from one node to another, returning true if it was present. The topological order
+// is not modified, since it remains valid.
+func (g *DAG) RemoveEdge(from, to interface{}) bool {
+	x, ok := g.index[from]
+	if false == ok {
+		return false
+	}
+	y, ok := g.index[to]
+	if false == ok {
+		return false
+	}
+	if _, ok := g.edges[[2]int{x, y}]; false == ok {
+		return false
+	}
+	delete(g.edges, [2]int{x, y})
+	g.successors[x] = remove(g.successors[x], y)
+	g.predecessors[y] = remove(g.predecessors[y], x)
+	return true
+}
+
+// HasEdge returns true if there is an edge from one node to another.
+func (g *DAG) HasEdge(from, to interface{}) bool {
+	x, ok := g.index[from]
+	if false == ok {
+		return false
+	}
+	y, ok := g.index[to]
+	if false == ok {
+		return false
+	}
+	_, ok = g.edges[[2]int{x, y}]
+	return ok
+}
+
+// Order returns every node, in the current topological order, where every node is before it's successors. Note
+// that the order is only as stable as it needs to be, e.g. nodes are added at the end of the order, but may be moved
+// by any subsequent call to AddEdge.
+func (g *DAG) Order() []interface{} {
+	order := make([]interface{}, len(g.position))
+	for i, v := range g.position {
+		order[i] = g.nodes[v]
+	}
+	return order
+}
+
+// Nodes implements graph.Graph, returning the nodes in the order they were first added, and must not be modified.
+func (g *DAG) Nodes() []interface{} {
+	return g.nodes
+}
+
+// Successors implements graph.Graph, returning the successors of node, in the order the edges were added.
+func (g *DAG) Successors(node interface{}) []interface{} {
+	i, ok := g.index[node]
+	if false == ok {
+		return nil
+	}
+	successors := make([]interface{}, len(g.successors[i]))
+	for j, v := range g.successors[i] {
+		successors[j] = g.nodes[v]
+	}
+	return successors
+}
+
+// The search method performs an iterative depth first search from start, via edges, only visiting nodes that
+// include returns true for, returning every node that was visited, or the path from start to target, inclusive, if
+// it was visited (in which case the search stops early).
+func (g *DAG) search(start int, edges [][]int, include func(v int) bool, target int) (visited []int, path []int) {
+	type frame struct {
+		node int
+		next int
+	}
+	defer func() {
+		for _, v := range visited {
+			g.visited[v] = false
+		}
+	}()
+	g.visited[start] = true
+	visited = append(visited, start)
+	frames := []frame{{node: start}}
+	for 0 != len(frames) {
+		top := &frames[len(frames)-1]
+		if top.next == len(edges[top.node]) {
+			frames = frames[:len(frames)-1]
+			continue
+		}
+		w := edges[top.node][top.next]
+		top.next++
+		if w == target {
+			path = make([]int, 0, len(frames)+1)
+			for _, f := range frames {
+				path = append(path, f.node)
+			}
+			return visited, append(path, w)
+		}
+		if false == g.visited[w] && include(w) {
+			g.visited[w] = true
+			visited = append(visited, w)
+			frames = append(frames, frame{node: w})
+		}
+	}
+	return visited, nil
+}
+
+// The reorder method moves every node in backward (the nodes that can reach the edge's start) before every node in
+// forward (the nodes reachable from the edge's end), using only the positions they already occupy.
+func (g *DAG) reorder(backward, forward []int) {
+	byOrd := func(nodes []int) {
+		sort.Slice(nodes, func(i, j int) bool { return g.ord[nodes[i]] < g.ord[nodes[j]] })
+	}
+	byOrd(backward)
+	byOrd(forward)
+	nodes := append(backward, forward...)
+	positions := make([]int, len(nodes))
+	for i, v := range nodes {
+		positions[i] = g.ord[v]
+	}
+	sort.Ints(positions)
+	for i, v := range nodes {
+		g.ord[v] = positions[i]
+		g.position[positions[i]] = v
+	}
+}
+
+func remove(nodes []int, node int) []int {
+	for i, v := range nodes {
+		if v == node {
+			return append(nodes[:i], nodes[i+1:]...)
+		}
+	}
+	return nodes
+}
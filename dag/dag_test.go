@@ -0,0 +1,154 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/tarjan"
+)
+
+// The validate function checks that the order is a valid topological order, of every node.
+func validate(t *testing.T, g *DAG) {
+	t.Helper()
+	order := g.Order()
+	position := make(map[interface{}]int)
+	for i, v := range order {
+		position[v] = i
+	}
+	if len(position) != len(g.Nodes()) || len(order) != len(g.Nodes()) {
+		t.Fatal(order, g.Nodes())
+	}
+	for _, a := range g.Nodes() {
+		for _, b := range g.Successors(a) {
+			if position[a] >= position[b] {
+				t.Fatal(order, a, b)
+			}
+		}
+	}
+	for v, i := range g.ord {
+		if g.position[i] != v || g.visited[v] {
+			t.Fatal(g.ord, g.position)
+		}
+	}
+}
+
+func TestDAG_zero(t *testing.T) {
+	var g DAG
+	if 0 != len(g.Order()) || nil != g.Nodes() || nil != g.Successors(1) || g.HasEdge(1, 2) || g.RemoveEdge(1, 2) {
+		t.Fatal(g)
+	}
+	var _ graph.Graph = &g
+}
+
+func TestDAG(t *testing.T) {
+	var g DAG
+	g.AddNode("c")
+	for _, e := range [][2]string{{"a", "b"}, {"b", "c"}, {"d", "a"}, {"a", "b"}, {"d", "c"}} {
+		if err := g.AddEdge(e[0], e[1]); nil != err {
+			t.Fatal(err)
+		}
+		validate(t, &g)
+	}
+	if s := fmt.Sprint(g.Nodes(), g.Order(), g.Successors("a"), g.Successors("d")); s != "[c a b d] [d a b c] [b] [a c]" {
+		t.Fatal(s)
+	}
+	err := g.AddEdge("c", "d")
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) || err.Error() != "cycle detected: c -> d -> a -> b -> c" {
+		t.Fatal(err)
+	}
+	if err := g.AddEdge("e", "e"); nil == err || err.Error() != "cycle detected: e -> e" {
+		t.Fatal(err)
+	}
+	// the node is still added
+	if s := fmt.Sprint(g.Order()); s != "[d a b c e]" || g.HasEdge("e", "e") || g.HasEdge("c", "d") {
+		t.Fatal(s)
+	}
+	if false == g.HasEdge("b", "c") || false == g.RemoveEdge("b", "c") || g.HasEdge("b", "c") || g.RemoveEdge("b", "c") {
+		t.Fatal()
+	}
+	if err := g.AddEdge("c", "d"); nil == err || err.Error() != "cycle detected: c -> d -> c" {
+		t.Fatal(err)
+	}
+	g.RemoveEdge("d", "c")
+	if err := g.AddEdge("c", "d"); nil != err {
+		t.Fatal(err)
+	}
+	validate(t, &g)
+	if s := fmt.Sprint(g.Order(), g.Successors("c")); s != "[c d a b e] [d]" {
+		t.Fatal(s)
+	}
+}
+
+func TestDAG_random(t *testing.T) {
+	rand.Seed(77123)
+	for x := 0; x < 100; x++ {
+		var (
+			g DAG
+			n = 1 + rand.Intn(30)
+		)
+		for i := 0; i < n; i++ {
+			g.AddNode(i)
+		}
+		for i := 0; i < 4*n; i++ {
+			from, to := rand.Intn(n), rand.Intn(n)
+			if 0 == rand.Intn(4) {
+				g.RemoveEdge(from, to)
+				validate(t, &g)
+				continue
+			}
+			// the graph, with the edge
+			var b graph.Adjacency
+			for _, v := range g.Nodes() {
+				b.AddNode(v)
+				for _, w := range g.Successors(v) {
+					b.AddEdge(v, w)
+				}
+			}
+			b.AddEdge(from, to)
+			existing := g.HasEdge(from, to)
+			err := g.AddEdge(from, to)
+			validate(t, &g)
+			if existing {
+				if nil != err {
+					t.Fatal(err)
+				}
+				continue
+			}
+			if acyclic := tarjan.Condense(&b).Acyclic(); acyclic != (nil == err) {
+				t.Fatal(x, from, to, err)
+			}
+			if nil == err {
+				continue
+			}
+			cycle := err.(*CycleError).Cycle
+			if cycle[0] != from || cycle[1] != to || cycle[len(cycle)-1] != from {
+				t.Fatal(cycle)
+			}
+			for i := 2; i < len(cycle); i++ {
+				if false == g.HasEdge(cycle[i-1], cycle[i]) {
+					t.Fatal(cycle)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,251 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package deepcopy provides a deep copy implementation for arbitrary Go values, that reproduces any shared
+// references, and therefore any cycles, exactly, in the copy, using reflect.
+//
+// Every pointer, map, and slice is copied at most once, with any other references to it, from anywhere within the
+// value, referring to that same copy. Note that slices are only considered to be the same reference if they share the
+// same underlying array, from the same offset, and have the same length (overlapping slices will be copied
+// separately), and that pointers to the fields or elements of other values are copied separately, from the value
+// that contains them.
+//
+// Unexported struct fields cannot be set using reflect, and so are copied shallowly (like an assignment would), as
+// are funcs, channels, and unsafe pointers. If a type requires different behavior, a Hook may be registered for it.
+package deepcopy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/joeycumines/go-detect-cycle/internal/ref"
+)
+
+type (
+	// Hook is a custom copy function, for a specific type, which must return a value of that type (or nil, if the
+	// type is a pointer, map, slice, or interface), and is passed a value of that type, which will never be nil. A
+	// Hook for a pointer, map, or slice type will be called at most once, for each distinct reference.
+	Hook func(src interface{}) (interface{}, error)
+
+	// Copier performs deep copies, and may be configured with a Hook for any number of types, the zero value being
+	// ready to use, without any hooks. Like the detectors, it's treated as immutable.
+	Copier struct {
+		hooks map[reflect.Type]Hook
+	}
+
+	// The copyState struct holds the state for a single call to Copier.Copy.
+	copyState struct {
+		hooks map[reflect.Type]Hook
+		// copies maps the ref.Key of every pointer, map, and slice copied so far, to it's copy.
+		copies map[ref.Key]reflect.Value
+	}
+)
+
+// Copy is Copier.Copy, without any hooks.
+func Copy(v interface{}) (interface{}, error) {
+	return Copier{}.Copy(v)
+}
+
+// WithHook returns a new Copier that is the same as the receiver, but with a Hook registered for the type t, which
+// replaces any existing Hook for that type.
+func (c Copier) WithHook(t reflect.Type, hook Hook) Copier {
+	if nil == t {
+		panic(errors.New("[Copier.WithHook] t must be non-nil"))
+	}
+	if nil == hook {
+		panic(errors.New("[Copier.WithHook] hook must be non-nil"))
+	}
+	hooks := make(map[reflect.Type]Hook, len(c.hooks)+1)
+	for k, v := range c.hooks {
+		hooks[k] = v
+	}
+	hooks[t] = hook
+	c.hooks = hooks
+	return c
+}
+
+// Copy returns a deep copy of v, of the same type, or the first error returned by a Hook, or a Hook returning a value
+// of the wrong type.
+func (c Copier) Copy(v interface{}) (interface{}, error) {
+	if nil == v {
+		return nil, nil
+	}
+	s := copyState{
+		hooks:  c.hooks,
+		copies: make(map[ref.Key]reflect.Value),
+	}
+	dst, err := s.copy(reflect.ValueOf(v))
+	if nil != err {
+		return nil, err
+	}
+	return dst.Interface(), nil
+}
+
+// The copy method returns a copy of src, which is not necessarily addressable or settable.
+func (s *copyState) copy(src reflect.Value) (reflect.Value, error) {
+	t := src.Type()
+
+	if hook, ok := s.hooks[t]; true == ok {
+		return s.copyHook(hook, src)
+	}
+
+	if 0 == len(s.hooks) && ref.Pointerless(t) {
+		return src, nil
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		key, ok := ref.KeyOf(src)
+		if false == ok {
+			// nil, or an empty slice, which is still copied, since it might have a capacity
+			if reflect.Slice == src.Kind() && false == src.IsNil() {
+				return reflect.MakeSlice(t, 0, src.Cap()), nil
+			}
+			return src, nil
+		}
+		if dst, ok := s.copies[key]; true == ok {
+			return dst, nil
+		}
+		switch src.Kind() {
+		case reflect.Ptr:
+			dst := reflect.New(t.Elem())
+			s.copies[key] = dst
+			return dst, s.copyInto(dst.Elem(), src.Elem())
+		case reflect.Map:
+			dst := reflect.MakeMapWithSize(t, src.Len())
+			s.copies[key] = dst
+			iter := src.MapRange()
+			for iter.Next() {
+				k, err := s.copy(iter.Key())
+				if nil != err {
+					return reflect.Value{}, err
+				}
+				v, err := s.copy(iter.Value())
+				if nil != err {
+					return reflect.Value{}, err
+				}
+				dst.SetMapIndex(k, v)
+			}
+			return dst, nil
+		default:
+			dst := reflect.MakeSlice(t, src.Len(), src.Cap())
+			s.copies[key] = dst
+			for i := 0; i < src.Len(); i++ {
+				if err := s.copyInto(dst.Index(i), src.Index(i)); nil != err {
+					return reflect.Value{}, err
+				}
+			}
+			return dst, nil
+		}
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return src, nil
+		}
+		v, err := s.copy(src.Elem())
+		if nil != err {
+			return reflect.Value{}, err
+		}
+		dst := reflect.New(t).Elem()
+		dst.Set(v)
+		return dst, nil
+
+	case reflect.Struct, reflect.Array:
+		dst := reflect.New(t).Elem()
+		return dst, s.copyInto(dst, src)
+
+	default:
+		return src, nil
+	}
+}
+
+// The copyInto method sets dst, which must be settable, to a copy of src, copying structs and arrays in place.
+func (s *copyState) copyInto(dst, src reflect.Value) error {
+	if _, ok := s.hooks[src.Type()]; false == ok {
+		switch src.Kind() {
+		case reflect.Struct:
+			// the shallow copy is necessary for any unexported fields
+			dst.Set(src)
+			for i := 0; i < src.NumField(); i++ {
+				if f := dst.Field(i); f.CanSet() {
+					if err := s.copyInto(f, src.Field(i)); nil != err {
+						return err
+					}
+				}
+			}
+			return nil
+		case reflect.Array:
+			for i := 0; i < src.Len(); i++ {
+				if err := s.copyInto(dst.Index(i), src.Index(i)); nil != err {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	v, err := s.copy(src)
+	if nil != err {
+		return err
+	}
+	dst.Set(v)
+	return nil
+}
+
+// The copyHook method copies src, using hook, which must return a value of the same type, registering the result,
+// if it's a reference.
+func (s *copyState) copyHook(hook Hook, src reflect.Value) (reflect.Value, error) {
+	t := src.Type()
+	key, isRef := ref.KeyOf(src)
+	if true == isRef {
+		if dst, ok := s.copies[key]; true == ok {
+			return dst, nil
+		}
+	}
+	switch src.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if src.IsNil() {
+			return src, nil
+		}
+	}
+	result, err := hook(src.Interface())
+	if nil != err {
+		return reflect.Value{}, err
+	}
+	dst := reflect.New(t).Elem()
+	if nil != result {
+		v := reflect.ValueOf(result)
+		if false == v.Type().AssignableTo(t) {
+			return reflect.Value{}, fmt.Errorf("deepcopy: hook for %s returned a %s", t, v.Type())
+		}
+		dst.Set(v)
+	} else if false == isNillable(t) {
+		return reflect.Value{}, fmt.Errorf("deepcopy: hook for %s returned nil", t)
+	}
+	if true == isRef {
+		s.copies[key] = dst
+	}
+	return dst, nil
+}
+
+// The isNillable function returns true if nil may be assigned to values of type t.
+func isNillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package deepcopy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	testNode struct {
+		Name     string
+		Children []*testNode
+		Parent   *testNode
+		Meta     map[string]interface{}
+		private  *testNode
+	}
+
+	testArrays struct {
+		A [2]*testNode
+		B [2]interface{}
+	}
+)
+
+func newTestTree() *testNode {
+	root := &testNode{Name: "root", Meta: map[string]interface{}{"tags": []string{"a"}}}
+	a := &testNode{Name: "a", Parent: root, Meta: root.Meta}
+	b := &testNode{Name: "b", Parent: root, private: a}
+	root.Children = []*testNode{a, b, a}
+	root.Meta["self"] = root.Meta
+	root.Meta["root"] = root
+	return root
+}
+
+func TestCopy_tree(t *testing.T) {
+	src := newTestTree()
+	v, err := Copy(src)
+	if nil != err {
+		t.Fatal(err)
+	}
+	dst := v.(*testNode)
+	if dst == src || dst.Name != "root" || 3 != len(dst.Children) || nil != dst.Parent {
+		t.Fatal(dst)
+	}
+	a, b := dst.Children[0], dst.Children[1]
+	if a == src.Children[0] || b == src.Children[1] || a != dst.Children[2] ||
+		"a" != a.Name || dst != a.Parent || "b" != b.Name || dst != b.Parent {
+		t.Fatal(a, b)
+	}
+	// unexported fields are shallow
+	if src.Children[0] != b.private {
+		t.Fatal(b.private)
+	}
+	// maps are shared, including cycles
+	if reflect.ValueOf(dst.Meta).Pointer() == reflect.ValueOf(src.Meta).Pointer() ||
+		reflect.ValueOf(dst.Meta).Pointer() != reflect.ValueOf(a.Meta).Pointer() ||
+		reflect.ValueOf(dst.Meta).Pointer() != reflect.ValueOf(dst.Meta["self"]).Pointer() ||
+		dst != dst.Meta["root"] {
+		t.Fatal(dst.Meta)
+	}
+	tags := dst.Meta["tags"].([]string)
+	if fmt.Sprint(tags) != "[a]" {
+		t.Fatal(tags)
+	}
+	tags[0] = "b"
+	if "a" != src.Meta["tags"].([]string)[0] {
+		t.Fatal()
+	}
+}
+
+func TestCopy_values(t *testing.T) {
+	now := time.Now()
+	for _, v := range []interface{}{
+		1,
+		"a",
+		[]int{1, 2},
+		[]int{},
+		map[string][]int{"a": {1}},
+		[3]int{1, 2, 3},
+		struct{ A, B int }{1, 2},
+		now,
+		&now,
+		[]interface{}{nil, 1, "b", []interface{}{}},
+		(*int)(nil),
+		map[string]int(nil),
+		[]int(nil),
+	} {
+		c, err := Copy(v)
+		if nil != err || false == reflect.DeepEqual(v, c) || reflect.TypeOf(v) != reflect.TypeOf(c) {
+			t.Errorf("%#v: %#v, %v", v, c, err)
+		}
+	}
+	if c, err := Copy(nil); nil != c || nil != err {
+		t.Fatal(c, err)
+	}
+}
+
+func TestCopy_slices(t *testing.T) {
+	s := make([]interface{}, 2, 5)
+	s[0] = s
+	s[1] = s[:1]
+	c, err := Copy(s)
+	if nil != err {
+		t.Fatal(err)
+	}
+	d := c.([]interface{})
+	if 2 != len(d) || 5 != cap(d) || reflect.ValueOf(d).Pointer() == reflect.ValueOf(s).Pointer() {
+		t.Fatal(d)
+	}
+	if e := d[0].([]interface{}); reflect.ValueOf(e).Pointer() != reflect.ValueOf(d).Pointer() || 2 != len(e) {
+		t.Fatal(e)
+	}
+	// overlapping slices are copied separately
+	if e := d[1].([]interface{}); reflect.ValueOf(e).Pointer() == reflect.ValueOf(d).Pointer() || 1 != len(e) {
+		t.Fatal(e)
+	}
+	if c, err := Copy(make([]int, 0, 3)); nil != err || 3 != cap(c.([]int)) {
+		t.Fatal(c, err)
+	}
+}
+
+func TestCopy_arrays(t *testing.T) {
+	n := &testNode{Name: "n"}
+	src := &testArrays{A: [2]*testNode{n, n}, B: [2]interface{}{n, [1]*testNode{n}}}
+	c, err := Copy(src)
+	if nil != err {
+		t.Fatal(err)
+	}
+	dst := c.(*testArrays)
+	if dst.A[0] == n || dst.A[0] != dst.A[1] || dst.B[0] != dst.A[0] || dst.B[1].([1]*testNode)[0] != dst.A[0] {
+		t.Fatal(dst)
+	}
+}
+
+func TestCopier_WithHook(t *testing.T) {
+	var calls int
+	c := Copier{}.WithHook(reflect.TypeOf(&testNode{}), func(src interface{}) (interface{}, error) {
+		calls++
+		return &testNode{Name: src.(*testNode).Name + "'"}, nil
+	})
+	if nil != (Copier{}).hooks || 1 != len(c.hooks) {
+		t.Fatal(c)
+	}
+	n := &testNode{Name: "n"}
+	v, err := c.Copy([]*testNode{n, n, nil})
+	if nil != err {
+		t.Fatal(err)
+	}
+	s := v.([]*testNode)
+	if 1 != calls || "n'" != s[0].Name || s[0] != s[1] || nil != s[2] {
+		t.Fatal(calls, s)
+	}
+	// hooks for value types apply within pointerless values
+	c = c.WithHook(reflect.TypeOf(0), func(src interface{}) (interface{}, error) {
+		return src.(int) + 1, nil
+	})
+	if v, err := c.Copy(struct{ A, B int }{1, 2}); nil != err || fmt.Sprint(v) != "{2 3}" {
+		t.Fatal(v, err)
+	}
+	if v, err := c.Copy([2]int{1, 2}); nil != err || fmt.Sprint(v) != "[2 3]" {
+		t.Fatal(v, err)
+	}
+}
+
+func TestCopier_WithHook_errors(t *testing.T) {
+	expected := errors.New("some error")
+	for _, tc := range []struct {
+		hook     Hook
+		expected string
+	}{
+		{func(interface{}) (interface{}, error) { return nil, expected }, "some error"},
+		{func(interface{}) (interface{}, error) { return "a", nil }, "deepcopy: hook for deepcopy.testArrays returned a string"},
+		{func(interface{}) (interface{}, error) { return nil, nil }, "deepcopy: hook for deepcopy.testArrays returned nil"},
+	} {
+		c := Copier{}.WithHook(reflect.TypeOf(testArrays{}), tc.hook)
+		if v, err := c.Copy(map[int]interface{}{1: &testArrays{}}); nil != v || nil == err || tc.expected != err.Error() {
+			t.Error(v, err)
+		}
+		if v, err := c.Copy([]testArrays{{}}); nil != v || nil == err || tc.expected != err.Error() {
+			t.Error(v, err)
+		}
+	}
+	// nil is fine for nillable types
+	c := Copier{}.WithHook(reflect.TypeOf(map[int]int{}), func(interface{}) (interface{}, error) { return nil, nil })
+	if v, err := c.Copy(map[int]int{1: 1}); nil != err || nil != v.(map[int]int) {
+		t.Fatal(v, err)
+	}
+}
+
+func TestCopier_WithHook_panic(t *testing.T) {
+	for _, tc := range []struct {
+		t        reflect.Type
+		hook     Hook
+		expected string
+	}{
+		{nil, func(interface{}) (interface{}, error) { return nil, nil }, "[Copier.WithHook] t must be non-nil"},
+		{reflect.TypeOf(0), nil, "[Copier.WithHook] hook must be non-nil"},
+	} {
+		func() {
+			defer func() {
+				if r := fmt.Sprint(recover()); r != tc.expected {
+					t.Error(r)
+				}
+			}()
+			Copier{}.WithHook(tc.t, tc.hook)
+		}()
+	}
+}
@@ -0,0 +1,262 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package feedback provides an approximation of the minimum feedback arc set of a directed graph, the edges that
+// must be removed to make it acyclic, answering the question of which dependencies should be removed, to break every
+// cycle. Finding the minimum is NP-hard, so the heuristic of Eades, Lin, and Smyth is used, which orders the nodes,
+// greedily, such that few edges point backwards, followed by a pass that restores any edges that are not required.
+package feedback
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+
+	"github.com/joeycumines/go-detect-cycle/dag"
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+type (
+	// Options configures ArcSet, the zero value being the default.
+	Options struct {
+		// Weight returns the cost of removing the edge from one node to another, which must not be negative, and
+		// defaults to one for every edge, if it's nil. Note that it may be called more than once for each edge.
+		Weight func(from, to interface{}) float64
+	}
+
+	// The candidate struct is an entry in the priority queue of nodes, for the greedy phase of the heuristic.
+	candidate struct {
+		node    int
+		delta   float64
+		version int
+	}
+
+	// The candidates type implements heap.Interface, as a max heap of delta, breaking ties by node.
+	candidates []candidate
+)
+
+// ArcSet is Options.ArcSet, using the default options.
+func ArcSet(g graph.Graph) []graph.Edge {
+	return Options{}.ArcSet(g)
+}
+
+// ArcSet returns a set of edges that, if removed from g, would leave it acyclic, which approximates the set with the
+// minimum total Weight, in the order they were returned by g (including any duplicates, every one of which must be
+// removed). Nodes are ordered using the heuristic of Eades, Lin, and Smyth, repeatedly moving sinks to the end, and
+// sources to the start, and otherwise the node with the greatest difference between the weight of it's outgoing and
+// incoming edges to the start, with every edge that points backwards (including every edge from a node to itself)
+// being a candidate for removal. Each candidate is then restored, in order of descending weight, unless it would
+// form a cycle, as determined by a dag.DAG, meaning no edge in the result is redundant, and the remaining edges are
+// verified to be acyclic. The result is nil if g is already acyclic, and is deterministic.
+func (o Options) ArcSet(g graph.Graph) []graph.Edge {
+	var (
+		d      = digraph.New(g)
+		n      = len(d.Nodes)
+		weight = o.weights(d)
+		order  = els(d, weight)
+	)
+
+	position := make([]int, n)
+	for i, v := range order {
+		position[v] = i
+	}
+
+	// every edge that points forwards is kept, and the rest are the candidates for removal
+	type edge struct {
+		from, to int
+		index    int
+	}
+	var (
+		kept    dag.DAG
+		removed []edge
+		count   int
+	)
+	for v := 0; v < n; v++ {
+		kept.AddNode(v)
+	}
+	for v, successors := range d.Successors {
+		for i, w := range successors {
+			if position[v] < position[w] {
+				if err := kept.AddEdge(v, w); nil != err {
+					panic(errors.New("[Options.ArcSet] unexpected cycle: " + err.Error()))
+				}
+			} else {
+				removed = append(removed, edge{v, w, count + i})
+			}
+		}
+		count += len(successors)
+	}
+	if 0 == len(removed) {
+		return nil
+	}
+
+	// restore every candidate that doesn't form a cycle, most expensive first
+	sort.SliceStable(removed, func(i, j int) bool {
+		return weight(removed[i].from, removed[i].to) > weight(removed[j].from, removed[j].to)
+	})
+	restored := make(map[[2]int]bool)
+	for _, e := range removed {
+		if restored[[2]int{e.from, e.to}] || (e.from != e.to && nil == kept.AddEdge(e.from, e.to)) {
+			restored[[2]int{e.from, e.to}] = true
+		}
+	}
+
+	var result []edge
+	for _, e := range removed {
+		if false == restored[[2]int{e.from, e.to}] {
+			result = append(result, e)
+		}
+	}
+	if 0 == len(result) {
+		return nil
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].index < result[j].index })
+	edges := make([]graph.Edge, len(result))
+	for i, e := range result {
+		edges[i] = graph.Edge{From: d.Nodes[e.from], To: d.Nodes[e.to]}
+	}
+	return edges
+}
+
+// The weights method returns a func, that returns the weight of an edge, by node index, using Weight.
+func (o Options) weights(d digraph.Graph) func(from, to int) float64 {
+	if nil == o.Weight {
+		return func(from, to int) float64 { return 1 }
+	}
+	return func(from, to int) float64 { return o.Weight(d.Nodes[from], d.Nodes[to]) }
+}
+
+// The els function returns the order of the nodes, using the heuristic of Eades, Lin, and Smyth, using weight for
+// the difference between the outgoing and incoming edges, but not for identifying sinks and sources.
+func els(d digraph.Graph, weight func(from, to int) float64) []int {
+	var (
+		n            = len(d.Nodes)
+		predecessors = make([][]int, n)
+		in           = make([]int, n)
+		out          = make([]int, n)
+		delta        = make([]float64, n)
+		version      = make([]int, n)
+		removed      = make([]bool, n)
+		sinks        []int
+		sources      []int
+		queue        candidates
+		start        []int
+		end          []int
+	)
+	for v, successors := range d.Successors {
+		for _, w := range successors {
+			if v == w {
+				continue
+			}
+			predecessors[w] = append(predecessors[w], v)
+			out[v]++
+			in[w]++
+			delta[v] += weight(v, w)
+			delta[w] -= weight(v, w)
+		}
+	}
+	for v := 0; v < n; v++ {
+		if 0 == out[v] {
+			sinks = append(sinks, v)
+		} else if 0 == in[v] {
+			sources = append(sources, v)
+		}
+		queue = append(queue, candidate{v, delta[v], 0})
+	}
+	heap.Init(&queue)
+
+	remove := func(v int) {
+		removed[v] = true
+		for _, w := range d.Successors[v] {
+			if v == w || removed[w] {
+				continue
+			}
+			in[w]--
+			delta[w] += weight(v, w)
+			version[w]++
+			heap.Push(&queue, candidate{w, delta[w], version[w]})
+			if 0 == in[w] {
+				sources = append(sources, w)
+			}
+		}
+		for _, u := range predecessors[v] {
+			if removed[u] {
+				continue
+			}
+			out[u]--
+			delta[u] -= weight(u, v)
+			version[u]++
+			heap.Push(&queue, candidate{u, delta[u], version[u]})
+			if 0 == out[u] {
+				sinks = append(sinks, u)
+			}
+		}
+	}
+
+	for remaining := n; 0 < remaining; {
+		switch {
+		case 0 != len(sinks):
+			v := sinks[0]
+			sinks = sinks[1:]
+			if false == removed[v] {
+				remove(v)
+				remaining--
+				end = append(end, v)
+			}
+		case 0 != len(sources):
+			v := sources[0]
+			sources = sources[1:]
+			if false == removed[v] {
+				remove(v)
+				remaining--
+				start = append(start, v)
+			}
+		default:
+			c := heap.Pop(&queue).(candidate)
+			if false == removed[c.node] && c.version == version[c.node] {
+				remove(c.node)
+				remaining--
+				start = append(start, c.node)
+			}
+		}
+	}
+
+	// the sinks were removed from the end, backwards
+	for i := len(end) - 1; i >= 0; i-- {
+		start = append(start, end[i])
+	}
+	return start
+}
+
+func (c candidates) Len() int { return len(c) }
+
+func (c candidates) Less(i, j int) bool {
+	if c[i].delta != c[j].delta {
+		return c[i].delta > c[j].delta
+	}
+	return c[i].node < c[j].node
+}
+
+func (c candidates) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+func (c *candidates) Push(x interface{}) { *c = append(*c, x.(candidate)) }
+
+func (c *candidates) Pop() interface{} {
+	v := (*c)[len(*c)-1]
+	*c = (*c)[:len(*c)-1]
+	return v
+}
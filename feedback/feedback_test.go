@@ -0,0 +1,177 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package feedback
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+	"github.com/joeycumines/go-detect-cycle/tarjan"
+)
+
+// The edges function returns a graph with the given edges, of the form "ab", for an edge from a to b.
+func edges(edges ...string) *graph.Adjacency {
+	var g graph.Adjacency
+	for _, e := range edges {
+		g.AddEdge(e[:1], e[1:])
+	}
+	return &g
+}
+
+// The without function returns g, without the given edges (removing every duplicate), and, optionally, with an
+// extra edge.
+func without(g graph.Graph, removed []graph.Edge, extra ...graph.Edge) *graph.Adjacency {
+	skip := make(map[graph.Edge]bool)
+	for _, e := range removed {
+		skip[e] = true
+	}
+	var result graph.Adjacency
+	for _, v := range g.Nodes() {
+		result.AddNode(v)
+		for _, w := range g.Successors(v) {
+			if false == skip[graph.Edge{From: v, To: w}] {
+				result.AddEdge(v, w)
+			}
+		}
+	}
+	for _, e := range extra {
+		result.AddEdge(e.From, e.To)
+	}
+	return &result
+}
+
+func TestArcSet(t *testing.T) {
+	for i, tc := range []struct {
+		Graph    *graph.Adjacency
+		Expected string
+	}{
+		{edges(), "[]"},
+		{edges("ab", "bc", "ac"), "[]"},
+		{edges("ab", "ba"), "[{b a}]"},
+		{edges("ab", "bc", "ca"), "[{c a}]"},
+		{edges("aa", "ab"), "[{a a}]"},
+		// duplicate edges count towards the weight
+		{edges("ab", "ba", "ba"), "[{a b}]"},
+		{edges("ab", "ab", "ba"), "[{b a}]"},
+		{edges("ab", "ba", "aa", "ab"), "[{a a} {b a}]"},
+		// b is in both cycles, with the most outgoing edges
+		{edges("ab", "bc", "ca", "bd", "db", "be"), "[{c a} {d b}]"},
+	} {
+		if s := fmt.Sprint(ArcSet(tc.Graph)); s != tc.Expected {
+			t.Error(i, s)
+		}
+	}
+}
+
+func TestOptions_ArcSet_weight(t *testing.T) {
+	g := edges("ab", "bc", "ca")
+	for _, tc := range []struct {
+		Cheap    graph.Edge
+		Expected string
+	}{
+		{graph.Edge{From: "a", To: "b"}, "[{a b}]"},
+		{graph.Edge{From: "b", To: "c"}, "[{b c}]"},
+		{graph.Edge{From: "c", To: "a"}, "[{c a}]"},
+	} {
+		weight := func(from, to interface{}) float64 {
+			if (graph.Edge{From: from, To: to}) == tc.Cheap {
+				return 1
+			}
+			return 10
+		}
+		if s := fmt.Sprint(Options{Weight: weight}.ArcSet(g)); s != tc.Expected {
+			t.Error(tc.Cheap, s)
+		}
+	}
+}
+
+func TestOptions_ArcSet_duplicates(t *testing.T) {
+	weight := func(from, to interface{}) float64 {
+		if "b" == from {
+			return 0.1
+		}
+		return 1
+	}
+	// every duplicate must be removed
+	if s := fmt.Sprint(Options{Weight: weight}.ArcSet(edges("ab", "ba", "bc", "ba"))); s != "[{b a} {b a}]" {
+		t.Fatal(s)
+	}
+}
+
+func TestEls(t *testing.T) {
+	// a source, a sink, and a cycle between them, where c has the greatest delta
+	d := digraph.New(edges("sa", "ab", "bc", "ca", "cb", "ct", "bt"))
+	order := els(d, func(from, to int) float64 { return 1 })
+	names := make([]interface{}, len(order))
+	for i, v := range order {
+		names[i] = d.Nodes[v]
+	}
+	if s := fmt.Sprint(names); s != "[s c a b t]" {
+		t.Fatal(s)
+	}
+}
+
+func TestArcSet_random(t *testing.T) {
+	rand.Seed(771239)
+	for x := 0; x < 300; x++ {
+		var (
+			g graph.Adjacency
+			n = 1 + rand.Intn(15)
+		)
+		for i := 0; i < n; i++ {
+			g.AddNode(i)
+		}
+		for i := rand.Intn(4 * n); i > 0; i-- {
+			g.AddEdge(rand.Intn(n), rand.Intn(n))
+		}
+		weights := make(map[graph.Edge]float64)
+		weight := func(from, to interface{}) float64 {
+			e := graph.Edge{From: from, To: to}
+			if _, ok := weights[e]; false == ok {
+				weights[e] = float64(rand.Intn(5))
+			}
+			return weights[e]
+		}
+		for _, o := range []Options{{}, {Weight: weight}} {
+			removed := o.ArcSet(&g)
+			if tarjan.Condense(&g).Acyclic() != (nil == removed) {
+				t.Fatal(x, removed)
+			}
+			if false == tarjan.Condense(without(&g, removed)).Acyclic() {
+				t.Fatal(x, removed)
+			}
+			// no edge is redundant
+			for i, e := range removed {
+				if i > 0 && removed[i-1] == e {
+					continue
+				}
+				var rest []graph.Edge
+				for _, f := range removed {
+					if f != e {
+						rest = append(rest, f)
+					}
+				}
+				if tarjan.Condense(without(&g, rest)).Acyclic() {
+					t.Fatal(x, removed, e)
+				}
+			}
+		}
+	}
+}
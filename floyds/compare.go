@@ -0,0 +1,103 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"reflect"
+)
+
+// CompareIdentity is the default compare function, used by NewDetector and NewBranchingDetector, if compare is nil.
+// It behaves exactly like `==` for values that are comparable, and never panics, for values that are not, comparing
+// them by identity, rather than by content:
+//
+//   - slices are identical if they share the same underlying array, from the same offset, and have the same length
+//   - maps are identical if they are the same map (the same as pointers)
+//   - funcs are identical only if they are both nil (the only comparison Go allows)
+//   - structs and arrays are identical if all of their fields or elements are, recursively
+//   - interfaces are identical if they are both nil, or their dynamic types are the same, and their values are
+//
+// This makes it suitable for detecting cycles between values like decoded JSON, where the same slice or map being
+// revisited is a cycle, but two slices or maps with the same content are not.
+func CompareIdentity(a, b interface{}) bool {
+	if nil == a || nil == b {
+		// comparing against a nil interface never panics
+		return a == b
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Func, reflect.Struct, reflect.Array:
+		return identical(reflect.ValueOf(a), reflect.ValueOf(b))
+	default:
+		return a == b
+	}
+}
+
+// CompareDeepEqual is a compare function that uses reflect.DeepEqual, which compares values by their content, and
+// never panics. Note that, unlike CompareIdentity, it treats two distinct slices or maps with the same content as
+// equal, and may visit the entirety of both values (it handles cyclic values), on every step.
+func CompareDeepEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// The identical function implements CompareIdentity for values of the same type.
+func identical(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Slice:
+		return a.Pointer() == b.Pointer() && a.Len() == b.Len()
+	case reflect.Map, reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Func:
+		return a.IsNil() && b.IsNil()
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		a, b = a.Elem(), b.Elem()
+		return a.Type() == b.Type() && identical(a, b)
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if false == identical(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if false == identical(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,192 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"encoding/json"
+	"testing"
+	"unsafe"
+)
+
+func TestCompareIdentity(t *testing.T) {
+	type (
+		comparable struct {
+			A int
+			B string
+		}
+		uncomparable struct {
+			A int
+			b []int
+		}
+		nested struct {
+			A interface{}
+			B [2]interface{}
+		}
+	)
+	var (
+		i1, i2    = 1, 1
+		slice     = []int{1, 2, 3}
+		sliceCopy = append([]int(nil), slice...)
+		m1, m2    = map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}
+		fn        = func() {}
+		ch        = make(chan int)
+		nilSlice  []int
+		nilMap    map[string]int
+		nilFunc   func()
+	)
+	for i, tc := range []struct {
+		a, b     interface{}
+		expected bool
+	}{
+		{nil, nil, true},
+		{nil, 1, false},
+		{1, nil, false},
+		{1, 1, true},
+		{1, 2, false},
+		{1, int64(1), false},
+		{"1", 1, false},
+		{"a", "a", true},
+		{1.5, 1.5, true},
+		{complex(1, 2), complex(1, 2), true},
+		{true, false, false},
+		{uint8(3), uint8(3), true},
+		{&i1, &i1, true},
+		{&i1, &i2, false},
+		{ch, ch, true},
+		{ch, make(chan int), false},
+		{unsafe.Pointer(&i1), unsafe.Pointer(&i1), true},
+		{comparable{1, "a"}, comparable{1, "a"}, true},
+		{comparable{1, "a"}, comparable{1, "b"}, false},
+		{slice, slice, true},
+		{slice, slice[:2], false},
+		{slice[1:], slice[1:], true},
+		{slice[1:], slice[:2], false},
+		{slice, sliceCopy, false},
+		{nilSlice, nilSlice, true},
+		{nilSlice, []int{}, false},
+		{m1, m1, true},
+		{m1, m2, false},
+		{nilMap, nilMap, true},
+		{fn, fn, false},
+		{nilFunc, nilFunc, true},
+		{nilFunc, fn, false},
+		{uncomparable{1, slice}, uncomparable{1, slice}, true},
+		{uncomparable{1, slice}, uncomparable{2, slice}, false},
+		{uncomparable{1, slice}, uncomparable{1, sliceCopy}, false},
+		{[2][]int{slice, slice}, [2][]int{slice, slice}, true},
+		{[2][]int{slice, slice}, [2][]int{slice, sliceCopy}, false},
+		{nested{A: m1}, nested{A: m1}, true},
+		{nested{A: m1}, nested{A: m2}, false},
+		{nested{A: m1}, nested{A: 1}, false},
+		{nested{A: m1}, nested{}, false},
+		{nested{}, nested{}, true},
+		{nested{B: [2]interface{}{1, slice}}, nested{B: [2]interface{}{1, slice}}, true},
+		{nested{B: [2]interface{}{1, slice}}, nested{B: [2]interface{}{1, sliceCopy}}, false},
+		{nested{B: [2]interface{}{1.5, "a"}}, nested{B: [2]interface{}{1.5, "a"}}, true},
+		{nested{B: [2]interface{}{1.5, "a"}}, nested{B: [2]interface{}{1.5, "b"}}, false},
+		{nested{A: uint(1)}, nested{A: uint(1)}, true},
+		{nested{A: complex64(1)}, nested{A: complex64(1)}, true},
+		{nested{A: false}, nested{A: false}, true},
+		{nested{A: &i1}, nested{A: &i1}, true},
+		{nested{A: fn}, nested{A: fn}, false},
+		{nested{A: struct{ A interface{} }{slice}}, nested{A: struct{ A interface{} }{slice}}, true},
+	} {
+		if actual := CompareIdentity(tc.a, tc.b); actual != tc.expected {
+			t.Errorf("%d: CompareIdentity(%#v, %#v) = %v, expected %v", i, tc.a, tc.b, actual, tc.expected)
+		}
+	}
+}
+
+func TestCompareDeepEqual(t *testing.T) {
+	slice := []int{1, 2, 3}
+	if false == CompareDeepEqual(slice, append([]int(nil), slice...)) ||
+		true == CompareDeepEqual(slice, slice[:2]) ||
+		false == CompareDeepEqual(map[string]interface{}{"a": []interface{}{1.0}}, map[string]interface{}{"a": []interface{}{1.0}}) ||
+		true == CompareDeepEqual(1, int64(1)) ||
+		false == CompareDeepEqual(nil, nil) {
+		t.Fatal()
+	}
+}
+
+func TestNewBranchingDetector_uncomparable(t *testing.T) {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(`{"a":{"b":[1,{"c":{}}]},"d":[[],[]]}`), &decoded); nil != err {
+		t.Fatal(err)
+	}
+	// introduce a cycle, from the innermost map, back to the outer one
+	decoded.(map[string]interface{})["a"].(map[string]interface{})["b"].([]interface{})[1].(map[string]interface{})["c"].(map[string]interface{})["e"] = decoded
+
+	var walk func(v interface{}, f BranchingDetector, depth int) bool
+	walk = func(v interface{}, f BranchingDetector, depth int) bool {
+		if depth > 100 {
+			t.Fatal(depth)
+		}
+		f = f.Hare(v)
+		defer f.Clear()
+		if false == f.Ok() {
+			return true
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for _, v := range v {
+				if walk(v, f, depth+1) {
+					return true
+				}
+			}
+		case []interface{}:
+			for _, v := range v {
+				if walk(v, f, depth+1) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if false == walk(decoded, NewBranchingDetector(nil, nil), 0) {
+		t.Fatal()
+	}
+
+	delete(decoded.(map[string]interface{}), "a")
+	if true == walk(decoded, NewBranchingDetector(nil, nil), 0) {
+		t.Fatal()
+	}
+}
+
+func TestNewDetector_compareModes(t *testing.T) {
+	// each step is a new slice, with the same content as the last
+	next := func(v interface{}) (interface{}, bool) {
+		return append([]int(nil), v.([]int)...), true
+	}
+	for _, tc := range []struct {
+		compare func(tortoise, hare interface{}) bool
+		cycle   bool
+	}{
+		{nil, false},
+		{CompareIdentity, false},
+		{CompareDeepEqual, true},
+	} {
+		f := NewDetector([]int{1}, next, tc.compare)
+		for x := 0; x < 20 && f.Ok(); x++ {
+			step, _ := next(f.hare)
+			f = f.Hare(step)
+		}
+		if tc.cycle == f.Ok() {
+			t.Fatal(tc.cycle)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// The errorPathHead and errorPathTail constants are the number of steps of CycleError.Path that are included by
+	// CycleError.Error, from the start and the end of the path, respectively, if it's longer than the sum of the two.
+	errorPathHead = 5
+	errorPathTail = 3
+	// The errorValueLength constant is the maximum number of runes any single value will be formatted with, by
+	// CycleError.Error, after which it will be truncated.
+	errorValueLength = 64
+)
+
+// CycleError models a detected cycle, as an error, and is returned by the Err methods of Detector and
+// BranchingDetector. It's always returned as a pointer, so it can be extracted, from any wrapping errors, like:
+//
+//	var cycleErr *floyds.CycleError
+//	if errors.As(err, &cycleErr) {
+//		// handle the cycle
+//	}
+type CycleError struct {
+	// HareCount is the number of steps the hare had taken, since the start, when the cycle was detected.
+	HareCount int
+	// TortoiseCount is the number of steps the tortoise had taken, since the start, when the cycle was detected.
+	TortoiseCount int
+	// Tortoise is the step the tortoise was on, which is equal to (as determined by compare) Hare.
+	Tortoise interface{}
+	// Hare is the step the hare was on, when it caught up to the tortoise.
+	Hare interface{}
+	// Path contains every step from Tortoise to Hare, inclusive, if it is available, which is only the case for
+	// BranchingDetector, since Detector does not store any previous steps. For BranchingDetector values constructed
+	// by NewExactBranchingDetector, this is the same as the return value of it's `Cycle` method, and, otherwise, it
+	// will be some (not necessarily the smallest) multiple of the cycle's length.
+	Path []interface{}
+}
+
+// Error implements the error interface, describing the cycle, including the path, if any, which is truncated, if it
+// is too long, as are the formatted representations of any values (which are formatted using `%v`).
+func (e *CycleError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		"cycle detected: hare (step %d) %s revisited tortoise (step %d) %s",
+		e.HareCount,
+		formatErrorValue(e.Hare),
+		e.TortoiseCount,
+		formatErrorValue(e.Tortoise),
+	)
+	if 0 == len(e.Path) {
+		return b.String()
+	}
+	b.WriteString(", path: ")
+	path := e.Path
+	if len(path) > errorPathHead+errorPathTail {
+		path = path[:errorPathHead]
+	}
+	for i, v := range path {
+		if 0 != i {
+			b.WriteString(" -> ")
+		}
+		b.WriteString(formatErrorValue(v))
+	}
+	if len(path) != len(e.Path) {
+		fmt.Fprintf(&b, " -> ... (%d steps omitted) ...", len(e.Path)-errorPathHead-errorPathTail)
+		for _, v := range e.Path[len(e.Path)-errorPathTail:] {
+			b.WriteString(" -> ")
+			b.WriteString(formatErrorValue(v))
+		}
+	}
+	return b.String()
+}
+
+// The formatErrorValue function formats a value for CycleError.Error, truncating it if it's too long.
+func formatErrorValue(v interface{}) string {
+	s := []rune(fmt.Sprintf("%v", v))
+	if len(s) > errorValueLength {
+		return string(s[:errorValueLength-3]) + "..."
+	}
+	return string(s)
+}
+
+// Err returns nil if no cycle has been detected (Ok returns true), otherwise it returns a *CycleError, describing
+// the tortoise and the hare, without any Path.
+func (f Detector) Err() error {
+	f.validate()
+	if true == f.ok {
+		return nil
+	}
+	return &CycleError{
+		HareCount:     f.hareCount,
+		TortoiseCount: f.tortoiseCount,
+		Tortoise:      f.tortoise,
+		Hare:          f.hare,
+	}
+}
+
+// Err returns nil if no cycle has been detected (Ok returns true), otherwise it returns a *CycleError, describing
+// the tortoise and the hare, including the Path between them, note that Err must be called prior to calling `Clear`,
+// on any BranchingDetector along that path (which will remove steps). For BranchingDetector values constructed by
+// NewExactBranchingDetector, the tortoise is the step that was revisited.
+func (f BranchingDetector) Err() error {
+	if err := f.f.Err(); nil == err {
+		return nil
+	}
+	if nil != f.path {
+		path := f.Cycle()
+		return &CycleError{
+			HareCount:     f.f.hareCount,
+			TortoiseCount: f.f.hareCount - len(path) + 1,
+			Tortoise:      path[0],
+			Hare:          f.f.hare,
+			Path:          path,
+		}
+	}
+	path := make([]interface{}, 0, len(f.next)+1)
+	path = append(path, f.f.tortoise)
+	path = append(path, f.next...)
+	return &CycleError{
+		HareCount:     f.f.hareCount,
+		TortoiseCount: f.f.tortoiseCount,
+		Tortoise:      f.f.tortoise,
+		Hare:          f.f.hare,
+		Path:          path,
+	}
+}
@@ -0,0 +1,160 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCycleError_Error(t *testing.T) {
+	for i, tc := range []struct {
+		err      *CycleError
+		expected string
+	}{
+		{
+			&CycleError{},
+			"cycle detected: hare (step 0) <nil> revisited tortoise (step 0) <nil>",
+		},
+		{
+			&CycleError{HareCount: 6, TortoiseCount: 3, Tortoise: "a", Hare: "a"},
+			"cycle detected: hare (step 6) a revisited tortoise (step 3) a",
+		},
+		{
+			&CycleError{HareCount: 2, TortoiseCount: 1, Tortoise: 1, Hare: 1, Path: []interface{}{1, 2}},
+			"cycle detected: hare (step 2) 1 revisited tortoise (step 1) 1, path: 1 -> 2",
+		},
+		{
+			&CycleError{Path: []interface{}{1, 2, 3, 4, 5, 6, 7, 8}},
+			"cycle detected: hare (step 0) <nil> revisited tortoise (step 0) <nil>, path: 1 -> 2 -> 3 -> 4 -> 5 -> 6 -> 7 -> 8",
+		},
+		{
+			&CycleError{Path: []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+			"cycle detected: hare (step 0) <nil> revisited tortoise (step 0) <nil>, path: 1 -> 2 -> 3 -> 4 -> 5 -> ... (1 steps omitted) ... -> 7 -> 8 -> 9",
+		},
+		{
+			&CycleError{Path: []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}},
+			"cycle detected: hare (step 0) <nil> revisited tortoise (step 0) <nil>, path: 0 -> 1 -> 2 -> 3 -> 4 -> ... (12 steps omitted) ... -> 17 -> 18 -> 19",
+		},
+		{
+			&CycleError{Hare: strings.Repeat("ab", 40)},
+			"cycle detected: hare (step 0) " + strings.Repeat("ab", 30) + "a... revisited tortoise (step 0) <nil>",
+		},
+	} {
+		if actual := tc.err.Error(); actual != tc.expected {
+			t.Errorf("%d: unexpected error:\n%s\n%s", i, actual, tc.expected)
+		}
+	}
+}
+
+func TestDetector_Err(t *testing.T) {
+	next := rhoNext(2, 3)
+	f := NewDetector(0, next, nil)
+	if nil != f.Err() {
+		t.Fatal()
+	}
+	var step interface{} = 0
+	for f.Ok() {
+		if nil != f.Err() {
+			t.Fatal()
+		}
+		step, _ = next(step)
+		f = f.Hare(step)
+	}
+	err := fmt.Errorf("wrapped: %w", f.Err())
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) {
+		t.Fatal(err)
+	}
+	if cycleErr.HareCount != f.HareCount() || cycleErr.TortoiseCount != f.TortoiseCount() ||
+		cycleErr.Hare != step || cycleErr.Tortoise != step || nil != cycleErr.Path {
+		t.Fatal(cycleErr)
+	}
+	if err.Error() != "wrapped: "+cycleErr.Error() {
+		t.Fatal(err)
+	}
+}
+
+func TestDetector_Err_panic(t *testing.T) {
+	defer func() {
+		if r := fmt.Sprint(recover()); r != "[Detector.validate] nil property encountered, use the constructor NewDetector" {
+			t.Fatal(r)
+		}
+	}()
+	_ = Detector{}.Err()
+}
+
+func TestBranchingDetector_Err(t *testing.T) {
+	for mu := 0; mu < 8; mu++ {
+		for lambda := 1; lambda < 8; lambda++ {
+			next := rhoNext(mu, lambda)
+			var step interface{} = 0
+			f := NewBranchingDetector(step, nil)
+			if nil != f.Err() {
+				t.Fatal()
+			}
+			for f.Ok() {
+				step, _ = next(step)
+				f = f.Hare(step)
+			}
+			var cycleErr *CycleError
+			if false == errors.As(f.Err(), &cycleErr) {
+				t.Fatal(mu, lambda)
+			}
+			if cycleErr.HareCount != f.HareCount() || cycleErr.TortoiseCount != f.TortoiseCount() ||
+				cycleErr.Hare != step || cycleErr.Tortoise != step ||
+				len(cycleErr.Path) != cycleErr.HareCount-cycleErr.TortoiseCount+1 ||
+				0 != (len(cycleErr.Path)-1)%lambda {
+				t.Fatal(mu, lambda, cycleErr)
+			}
+			// the path must be the actual steps
+			v := interface{}(0)
+			for x := 0; x < cycleErr.TortoiseCount; x++ {
+				v, _ = next(v)
+			}
+			for i, p := range cycleErr.Path {
+				if p != v {
+					t.Fatal(mu, lambda, i, cycleErr.Path)
+				}
+				v, _ = next(v)
+			}
+		}
+	}
+}
+
+func TestBranchingDetector_Err_exact(t *testing.T) {
+	f := NewExactBranchingDetector("a", identityKey)
+	for _, v := range []string{"b", "c", "d", "b"} {
+		if nil != f.Err() {
+			t.Fatal()
+		}
+		f = f.Hare(v)
+	}
+	var cycleErr *CycleError
+	if false == errors.As(f.Err(), &cycleErr) {
+		t.Fatal()
+	}
+	if 4 != cycleErr.HareCount || 1 != cycleErr.TortoiseCount || "b" != cycleErr.Tortoise || "b" != cycleErr.Hare ||
+		fmt.Sprint(cycleErr.Path) != "[b c d b]" {
+		t.Fatal(cycleErr)
+	}
+	if cycleErr.Error() != "cycle detected: hare (step 4) b revisited tortoise (step 1) b, path: b -> c -> d -> b" {
+		t.Fatal(cycleErr.Error())
+	}
+}
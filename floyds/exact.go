@@ -0,0 +1,135 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"errors"
+)
+
+type (
+	// The pathSet struct holds the key func, and the number of times each key is present, across every path that
+	// shares the same start (the counts are only ever an over-estimate, they are verified against the actual path).
+	pathSet struct {
+		key   func(step interface{}) interface{}
+		count map[interface{}]int
+	}
+
+	// The pathNode struct is an element of an immutable linked list, from a step back to the start, which is shared
+	// between branches, and stores the node that was revisited, if any.
+	pathNode struct {
+		set     *pathSet
+		parent  *pathNode
+		step    interface{}
+		key     interface{}
+		revisit *pathNode
+		cleared bool
+	}
+)
+
+// NewExactBranchingDetector constructs a new BranchingDetector with the given start value, that, rather than using
+// Floyd's algorithm, keeps track of the key of every step on the path from the start to the current step, which is
+// resolved using the (required) key func, and must be comparable (it's used as a map key). This means that a cycle is
+// detected on the very first revisit of a key (rather than up to twice the cycle length later), and that the exact
+// steps that form the cycle are available, via `Cycle`, at the cost of calling key once for each step, and O(n) memory
+// usage, where n is the length of the current path.
+//
+// Unlike the default mode, it is important that `Clear` is called only once the BranchingDetector (and any derived
+// from it) will no longer be used, as it removes the step from the set, however, a missing call to `Clear` will only
+// cost performance, since every hit in the set is verified, by walking the path. Note that the set is shared between
+// all branches, and is therefore not safe to use concurrently, from multiple goroutines.
+func NewExactBranchingDetector(start interface{}, key func(step interface{}) interface{}) BranchingDetector {
+	if nil == key {
+		panic(errors.New("[NewExactBranchingDetector] key must be non-nil"))
+	}
+	set := &pathSet{
+		key:   key,
+		count: make(map[interface{}]int),
+	}
+	node := set.node(nil, start)
+	return BranchingDetector{
+		f: NewDetector(
+			start,
+			emptyNext,
+			nil,
+		),
+		path: node,
+	}
+}
+
+// The node method returns a new pathNode for step, adding it to the set, and searching the parent path for any
+// revisit, only if it's key is (potentially) already present.
+func (s *pathSet) node(parent *pathNode, step interface{}) *pathNode {
+	n := &pathNode{
+		set:    s,
+		parent: parent,
+		step:   step,
+		key:    s.key(step),
+	}
+	if 0 != s.count[n.key] {
+		for p := parent; nil != p; p = p.parent {
+			if p.key == n.key {
+				n.revisit = p
+				break
+			}
+		}
+	}
+	s.count[n.key]++
+	return n
+}
+
+// The clear method removes the node's key from the set, and is safe to call multiple times.
+func (n *pathNode) clear() {
+	if true == n.cleared {
+		return
+	}
+	n.cleared = true
+	if c := n.set.count[n.key] - 1; 0 < c {
+		n.set.count[n.key] = c
+	} else {
+		delete(n.set.count, n.key)
+	}
+}
+
+// The hareExact method implements Hare, for BranchingDetector values constructed by NewExactBranchingDetector, note
+// that the internal Detector is used only to track the state (it's tortoise never moves).
+func (f BranchingDetector) hareExact(step interface{}) BranchingDetector {
+	f.path = f.path.set.node(f.path, step)
+	f.clear = f.path.clear
+	f.f.hare = step
+	f.f.hareCount++
+	f.f.ok = nil == f.path.revisit
+	return f
+}
+
+// Cycle returns the exact steps that form the cycle, starting with the step that was revisited, and ending with the
+// current step (which will have the same key), or nil, if no cycle has been detected, or the BranchingDetector was not
+// constructed using NewExactBranchingDetector. The length of the cycle is therefore `len(f.Cycle()) - 1`.
+func (f BranchingDetector) Cycle() []interface{} {
+	f.f.validate()
+	if nil == f.path || nil == f.path.revisit {
+		return nil
+	}
+	var size int
+	for n := f.path; n != f.path.revisit; n = n.parent {
+		size++
+	}
+	cycle := make([]interface{}, size+1)
+	for n := f.path; size >= 0; n, size = n.parent, size-1 {
+		cycle[size] = n.step
+	}
+	return cycle
+}
@@ -0,0 +1,200 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func identityKey(step interface{}) interface{} {
+	return step
+}
+
+func TestNewExactBranchingDetector_panic(t *testing.T) {
+	defer func() {
+		if r := fmt.Sprint(recover()); r != "[NewExactBranchingDetector] key must be non-nil" {
+			t.Fatal(r)
+		}
+	}()
+	NewExactBranchingDetector(0, nil)
+}
+
+func TestNewExactBranchingDetector(t *testing.T) {
+	f := NewExactBranchingDetector(1, identityKey)
+	if false == f.Ok() || 0 != f.HareCount() || 0 != f.TortoiseCount() || nil != f.Cycle() ||
+		nil == f.path || 1 != f.path.set.count[1] || nil != f.path.parent || 1 != f.path.step {
+		t.Fatal(f)
+	}
+}
+
+func TestBranchingDetector_Cycle_notExact(t *testing.T) {
+	f := NewBranchingDetector(0, nil)
+	for _, v := range []int{1, 2, 1, 2, 1, 2} {
+		f = f.Hare(v)
+	}
+	if true == f.Ok() || nil != f.Cycle() {
+		t.Fatal(f)
+	}
+}
+
+func TestBranchingDetector_Cycle_panic(t *testing.T) {
+	defer func() {
+		if r := fmt.Sprint(recover()); r != "[Detector.validate] nil property encountered, use the constructor NewDetector" {
+			t.Fatal(r)
+		}
+	}()
+	BranchingDetector{}.Cycle()
+}
+
+func TestBranchingDetector_Hare_exactFirstRevisit(t *testing.T) {
+	for mu := 0; mu < 10; mu++ {
+		for lambda := 1; lambda < 10; lambda++ {
+			next := rhoNext(mu, lambda)
+			var step interface{} = 0
+			f := NewExactBranchingDetector(step, identityKey)
+			for f.Ok() {
+				if f.HareCount() > mu+lambda {
+					t.Fatal(mu, lambda, f.HareCount())
+				}
+				step, _ = next(step)
+				f = f.Hare(step)
+			}
+			if f.HareCount() != mu+lambda {
+				t.Fatal(mu, lambda, f.HareCount())
+			}
+			cycle := f.Cycle()
+			if len(cycle) != lambda+1 || cycle[0] != mu || cycle[lambda] != mu {
+				t.Fatal(mu, lambda, cycle)
+			}
+			for i, v := range cycle[:lambda] {
+				if v != mu+i {
+					t.Fatal(mu, lambda, cycle)
+				}
+			}
+			// no further steps are taken
+			if g := f.Hare(-1); g.HareCount() != f.HareCount() || len(g.Cycle()) != lambda+1 {
+				t.Fatal(mu, lambda)
+			}
+		}
+	}
+}
+
+func TestBranchingDetector_Hare_exactSelf(t *testing.T) {
+	f := NewExactBranchingDetector("a", identityKey).Hare("a")
+	if true == f.Ok() || 1 != f.HareCount() {
+		t.Fatal(f)
+	}
+	if cycle := f.Cycle(); 2 != len(cycle) || "a" != cycle[0] || "a" != cycle[1] {
+		t.Fatal(cycle)
+	}
+}
+
+func TestBranchingDetector_Hare_exactBranches(t *testing.T) {
+	f := NewExactBranchingDetector(0, identityKey)
+	a := f.Hare(1).Hare(2)
+	b := f.Hare(2).Hare(1)
+	if false == a.Ok() || false == b.Ok() {
+		t.Fatal()
+	}
+	// both branches contain 1 and 2, but neither contains them twice
+	if 2 != f.path.set.count[1] || 2 != f.path.set.count[2] {
+		t.Fatal(f.path.set.count)
+	}
+	if c := a.Hare(3).Hare(1).Cycle(); 4 != len(c) || 1 != c[0] || 2 != c[1] || 3 != c[2] || 1 != c[3] {
+		t.Fatal(c)
+	}
+	if c := b.Hare(0).Cycle(); 4 != len(c) || 0 != c[0] || 2 != c[1] || 1 != c[2] || 0 != c[3] {
+		t.Fatal(c)
+	}
+}
+
+func TestBranchingDetector_Clear_exact(t *testing.T) {
+	f := NewExactBranchingDetector(0, identityKey)
+	a := f.Hare(1)
+	b := a.Hare(2)
+	c := f.Hare(2)
+	if 1 != f.path.set.count[0] || 1 != f.path.set.count[1] || 2 != f.path.set.count[2] {
+		t.Fatal(f.path.set.count)
+	}
+	b.Clear()
+	b.Clear()
+	if 1 != f.path.set.count[2] {
+		t.Fatal(f.path.set.count)
+	}
+	c.Clear()
+	a.Clear()
+	if 1 != len(f.path.set.count) || 1 != f.path.set.count[0] {
+		t.Fatal(f.path.set.count)
+	}
+	// the start has no clear
+	f.Clear()
+	if 1 != f.path.set.count[0] {
+		t.Fatal(f.path.set.count)
+	}
+}
+
+func TestBranchingDetector_Hare_exactKey(t *testing.T) {
+	type node struct {
+		name     string
+		children []*node
+	}
+	var (
+		root  = &node{name: "root"}
+		child = &node{name: "child", children: []*node{{name: "root"}, root}}
+	)
+	root.children = []*node{child}
+	var calls int
+	key := func(step interface{}) interface{} {
+		calls++
+		return step.(*node)
+	}
+	f := NewExactBranchingDetector(root, key).Hare(child)
+	// same name, different node
+	if g := f.Hare(child.children[0]); false == g.Ok() {
+		t.Fatal()
+	}
+	g := f.Hare(child.children[1])
+	if true == g.Ok() || 4 != calls {
+		t.Fatal(calls)
+	}
+	if c := g.Cycle(); 3 != len(c) || root != c[0] || child != c[1] || root != c[2] {
+		t.Fatal(c)
+	}
+}
+
+func TestNewExactBranchingDetector_generateCycleMap(t *testing.T) {
+	rand.Seed(41212399)
+	for _, callClear := range []bool{true, false} {
+		for x := 0; x < 10; x++ {
+			m := generateCycleMap(50, 0, 120)
+			if a, _ := mapHasCycle(m, NewExactBranchingDetector(nil, identityKey), callClear); false != a {
+				s, _ := json.MarshalIndent(m, "", "    ")
+				t.Fatal(string(s))
+			}
+		}
+		for x := 0; x < 200; x++ {
+			m := generateCycleMap(99, 1, 120)
+			if a, _ := mapHasCycle(m, NewExactBranchingDetector(nil, identityKey), callClear); true != a {
+				s, _ := json.MarshalIndent(m, "", "    ")
+				t.Fatal(string(s))
+			}
+		}
+	}
+}
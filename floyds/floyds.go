@@ -35,23 +35,23 @@ It's worth mentioning that this implementation treats the current state as immut
 at the expense of garbage collection. That aside, the memory cost of the Detector struct itself is O(1), one of the
 advantages of the algorithm used.
 
-I may implement the rest of the algorithm at a later date, which will probably include other types of cycle detection,
-if it happens.
-
 Usage:
 
-	- Create with `NewDetector`, providing start and next, optionally providing compare (defaults to equality).
+	- Create with `NewDetector`, providing start and next, optionally providing compare (defaults to identity).
 	- Increment with either `Hare` OR `Tortoise`, using `Ok` check for cycles, and passing down the new structs.
 	- If you are using `Tortoise` method, you will want to indicate done (out of bounds), by make it return false
 		from the next method, or you may not get the results you expect.
+	- Once `Ok` returns false, call `Result` to find where the cycle starts (mu), and how long it is (lambda).
 
 Branching Logic:
 
 If your algorithm has branching logic, where it forms a directed graph (and you only care about cycles from the
 current leaf to the root), please use the `BranchingDetector` struct, by calling it's constructor
-`NewBranchingDetector`.
+`NewBranchingDetector`, or `NewExactBranchingDetector`, if you can provide a comparable key for each step, and need
+the cycle to be detected as early as possible, or need to know exactly which steps form the cycle.
 
-Floyd's Tortoise and Hare algorithm, for reference. Only the first segment is currently implemented.
+Floyd's Tortoise and Hare algorithm, for reference. The first segment is implemented by `Hare` and `Tortoise`, and
+the remaining two segments are implemented by `Result`, which uses next and the start value.
 
 https://en.wikipedia.org/wiki/Cycle_detection
 
@@ -98,6 +98,7 @@ https://en.wikipedia.org/wiki/Cycle_detection
 type Detector struct {
 	next          func(v interface{}) (step interface{}, ok bool)
 	compare       func(tortoise, hare interface{}) bool
+	start         interface{}
 	tortoise      interface{}
 	hare          interface{}
 	ok            bool
@@ -106,21 +107,17 @@ type Detector struct {
 	tortoiseCount int
 }
 
-// The default compare function simply compares equality.
-func compareEquality(a, b interface{}) bool {
-	return a == b
-}
-
 // NewDetector constructs a new Detector struct, and must provide the start step, and function to resolve the next
-// step (from the previous step each time), and may optionally include a custom comparison method.
+// step (from the previous step each time), and may optionally include a custom comparison method, which defaults to
+// CompareIdentity, see also CompareDeepEqual.
 func NewDetector(start interface{}, next func(v interface{}) (interface{}, bool), compare func(tortoise, hare interface{}) bool) Detector {
 	if nil == next {
 		panic(errors.New("[NewDetector] next must be non-nil"))
 	}
 	if nil == compare {
-		compare = compareEquality
+		compare = CompareIdentity
 	}
-	return Detector{next, compare, start, start, true, false, 0, 0}
+	return Detector{next, compare, start, start, start, true, false, 0, 0}
 }
 
 func (f Detector) validate() {
@@ -267,13 +264,69 @@ func (f Detector) Done() bool {
 	return f.done
 }
 
+// Result models the location and length of a cycle, as found by Detector.Result.
+type Result struct {
+	// Mu is the index of the first step that is part of the cycle, where the start step has index 0.
+	Mu int
+	// Lambda is the length of the cycle, also known as the period.
+	Lambda int
+	// Entry is the step at index Mu, the first step that is part of the cycle.
+	Entry interface{}
+	// Meeting is the step the hare was on, when it caught up to the tortoise.
+	Meeting interface{}
+}
+
+// Result runs the remaining segments of Floyd's algorithm, to find the start (mu) and length (lambda) of the cycle,
+// using next and the start value. The ok return value will be false if no cycle has been detected yet, or if next
+// returned false while stepping, which should only be possible if next is not consistent with the steps that were
+// passed to `Hare` or `Tortoise`. Note that next will be called up to mu + lambda times, for each of the hare and
+// the tortoise, and, if the steps taken were not actually generated by next, it may never return.
+func (f Detector) Result() (result Result, ok bool) {
+	f.validate()
+	if true == f.ok {
+		return Result{}, false
+	}
+
+	result.Meeting = f.hare
+
+	// the distance between the meeting point and the start is a multiple of lambda, so moving the hare and the
+	// tortoise (reset to the start) at the same speed, they will meet at mu
+	tortoise, hare := f.start, f.hare
+	for false == f.compare(tortoise, hare) {
+		if tortoise, ok = f.next(tortoise); false == ok {
+			return Result{}, false
+		}
+		if hare, ok = f.next(hare); false == ok {
+			return Result{}, false
+		}
+		result.Mu++
+	}
+	result.Entry = tortoise
+
+	// the hare moves one step at a time while the tortoise stays put, until they are equal again
+	result.Lambda = 1
+	if hare, ok = f.next(tortoise); false == ok {
+		return Result{}, false
+	}
+	for false == f.compare(tortoise, hare) {
+		if hare, ok = f.next(hare); false == ok {
+			return Result{}, false
+		}
+		result.Lambda++
+	}
+
+	return result, true
+}
+
 // BranchingDetector uses the same logic as Detector (which implements the tortoise and the hare), but with the
 // addition of the ability to support branching logic, at the cost of something like O(n) memory usage, but can be
-// used with a simple stepper, that simply gets passed each step sequentially.
+// used with a simple stepper, that simply gets passed each step sequentially. See also NewExactBranchingDetector, for
+// a mode that detects cycles on the very first revisit, and can report exactly which steps form the cycle.
 type BranchingDetector struct {
 	f     Detector
 	next  []interface{}
 	clear func()
+	path  *pathNode
 }
 
 // The emptyNext function is a placeholder to avoid triggering a panic.
@@ -282,7 +335,7 @@ func emptyNext(interface{}) (interface{}, bool) {
 }
 
 // NewBranchingDetector constructs a new BranchingDetector with the given start value, and optionally a custom
-// comparison func, to determine if there was a cycle.
+// comparison func, to determine if there was a cycle, which defaults to CompareIdentity, see also CompareDeepEqual.
 func NewBranchingDetector(start interface{}, compare func(tortoise, hare interface{}) bool) BranchingDetector {
 	return BranchingDetector{
 		f: NewDetector(
@@ -366,6 +419,9 @@ func (f BranchingDetector) Hare(step interface{}) BranchingDetector {
 	if false == f.f.ok || true == f.f.done {
 		return f
 	}
+	if nil != f.path {
+		return f.hareExact(step)
+	}
 	updater := new(nextUpdater)
 	// At this point, updater.next has step as it's last value, and f.next might point to a different array.
 	updater.next = append(f.next, step)
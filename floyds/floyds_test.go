@@ -949,6 +949,114 @@ func TestDetector_hare_short(t *testing.T) {
 	}
 }
 
+// rhoNext returns a next function for the sequence 0, 1, 2, ..., which returns to mu after mu + lambda - 1.
+func rhoNext(mu, lambda int) func(v interface{}) (interface{}, bool) {
+	return func(v interface{}) (interface{}, bool) {
+		n := v.(int) + 1
+		if n >= mu+lambda {
+			n = mu
+		}
+		return n, true
+	}
+}
+
+func TestDetector_Result_hare(t *testing.T) {
+	for mu := 0; mu < 20; mu++ {
+		for lambda := 1; lambda < 20; lambda++ {
+			next := rhoNext(mu, lambda)
+			f := NewDetector(0, next, nil)
+			step := interface{}(0)
+			for x := 0; f.Ok(); x++ {
+				if x > 2*(mu+lambda) {
+					t.Fatal(mu, lambda)
+				}
+				if _, ok := f.Result(); true == ok {
+					t.Fatal(mu, lambda)
+				}
+				step, _ = next(step)
+				f = f.Hare(step)
+			}
+			result, ok := f.Result()
+			if false == ok || mu != result.Mu || lambda != result.Lambda || mu != result.Entry || f.hare != result.Meeting {
+				t.Fatal(mu, lambda, ok, result)
+			}
+		}
+	}
+}
+
+func TestDetector_Result_tortoise(t *testing.T) {
+	for mu := 0; mu < 20; mu++ {
+		for lambda := 1; lambda < 20; lambda++ {
+			next := rhoNext(mu, lambda)
+			f := NewDetector(0, next, nil)
+			step := interface{}(0)
+			for x := 0; f.Ok(); x++ {
+				if x > mu+lambda {
+					t.Fatal(mu, lambda)
+				}
+				step, _ = next(step)
+				f = f.Tortoise(step)
+			}
+			result, ok := f.Result()
+			if false == ok || mu != result.Mu || lambda != result.Lambda || mu != result.Entry || f.hare != result.Meeting {
+				t.Fatal(mu, lambda, ok, result)
+			}
+		}
+	}
+}
+
+func TestDetector_Result_compare(t *testing.T) {
+	list := []string{"a", "b", "c", "d", "b", "c", "d", "b", "c", "d", "b", "c", "d"}
+	next := func(v interface{}) (interface{}, bool) {
+		return v.(int) + 1, true
+	}
+	compare := func(a, b interface{}) bool {
+		return list[a.(int)] == list[b.(int)]
+	}
+	f := NewDetector(0, next, compare)
+	for f.Ok() {
+		f = f.Hare(f.hare.(int) + 1)
+	}
+	result, ok := f.Result()
+	if false == ok || 1 != result.Mu || 3 != result.Lambda || 1 != result.Entry {
+		t.Fatal(ok, result)
+	}
+}
+
+func TestDetector_Result_nextNotOk(t *testing.T) {
+	for _, limit := range []int{0, 1, 3, 6} {
+		count := 0
+		f := NewDetector(0, rhoNext(2, 5), nil)
+		for f.Ok() {
+			f = f.Tortoise(f.tortoise.(int) + 1)
+		}
+		f = f.SetNext(func(v interface{}) (interface{}, bool) {
+			count++
+			if count > limit {
+				return nil, false
+			}
+			return rhoNext(2, 5)(v)
+		})
+		if result, ok := f.Result(); false != ok || (Result{}) != result {
+			t.Fatal(limit, ok, result)
+		}
+	}
+}
+
+func TestDetector_Result_panic(t *testing.T) {
+	f := Detector{}
+	func() {
+		defer func() {
+			if err, ok := recover().(error); false == ok || nil == err ||
+				"[Detector.validate] nil property encountered, use the constructor NewDetector" != err.Error() {
+				t.Fatal()
+			}
+		}()
+		f.Result()
+		t.Fatal()
+	}()
+}
+
 func Test_emptyNext(t *testing.T) {
 	n, ok := emptyNext(12)
 	if nil != n || false != ok {
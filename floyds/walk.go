@@ -0,0 +1,66 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"errors"
+)
+
+// SkipChildren may be returned by the visit func passed to Walk, to indicate that the children of the node should not
+// be walked, it's never returned by Walk itself.
+var SkipChildren = errors.New("skip children")
+
+// Walk performs a depth-first (pre-order) traversal of the graph starting at root, using a BranchingDetector, calling
+// visit for each node, with the depth of that node (root has depth 0), before resolving and walking it's children, in
+// order. The traversal stops at the first error returned by visit (other than SkipChildren), which will be returned
+// as-is, or the first cycle detected, in which case a *CycleError will be returned, with it's Path populated.
+//
+// Note that, since Floyd's algorithm is used, nodes that form a cycle may be visited more than once, before the cycle
+// is detected, and that nodes that are reachable via multiple paths (but do not form a cycle) will be visited once per
+// path. The same defaults as NewBranchingDetector apply, i.e. nodes are compared using CompareIdentity. Both children
+// and visit must be non-nil.
+func Walk(root interface{}, children func(node interface{}) []interface{}, visit func(node interface{}, depth int) error) error {
+	if nil == children {
+		panic(errors.New("[Walk] children must be non-nil"))
+	}
+	if nil == visit {
+		panic(errors.New("[Walk] visit must be non-nil"))
+	}
+	return walk(NewBranchingDetector(root, nil), root, 0, children, visit)
+}
+
+// The walk function implements Walk, for a node that has already been passed to f (as the start, or via Hare).
+func walk(f BranchingDetector, node interface{}, depth int, children func(node interface{}) []interface{}, visit func(node interface{}, depth int) error) error {
+	if err := visit(node, depth); nil != err {
+		if SkipChildren == err {
+			return nil
+		}
+		return err
+	}
+	for _, child := range children(node) {
+		g := f.Hare(child)
+		err := g.Err()
+		if nil == err {
+			err = walk(g, child, depth+1, children, visit)
+		}
+		g.Clear()
+		if nil != err {
+			return err
+		}
+	}
+	return nil
+}
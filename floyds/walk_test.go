@@ -0,0 +1,176 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package floyds
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// The graphChildren function returns a children func for Walk, from an adjacency map.
+func graphChildren(graph map[int][]int) func(node interface{}) []interface{} {
+	return func(node interface{}) []interface{} {
+		var children []interface{}
+		for _, child := range graph[node.(int)] {
+			children = append(children, child)
+		}
+		return children
+	}
+}
+
+func TestWalk_tree(t *testing.T) {
+	var visited []string
+	err := Walk(
+		1,
+		graphChildren(map[int][]int{
+			1: {2, 5},
+			2: {3, 4},
+			5: {6},
+			6: {3},
+		}),
+		func(node interface{}, depth int) error {
+			visited = append(visited, fmt.Sprintf("%v@%d", node, depth))
+			return nil
+		},
+	)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if s := fmt.Sprint(visited); s != "[1@0 2@1 3@2 4@2 5@1 6@2 3@3]" {
+		t.Fatal(s)
+	}
+}
+
+func TestWalk_cycle(t *testing.T) {
+	var visits int
+	err := Walk(
+		1,
+		graphChildren(map[int][]int{
+			1: {2, 3},
+			2: {4},
+			3: {4},
+			4: {5},
+			5: {6},
+			6: {3},
+		}),
+		func(node interface{}, depth int) error {
+			visits++
+			if depth > 20 {
+				t.Fatal(depth)
+			}
+			return nil
+		},
+	)
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) {
+		t.Fatal(err)
+	}
+	if cycleErr.Tortoise != cycleErr.Hare || len(cycleErr.Path) != cycleErr.HareCount-cycleErr.TortoiseCount+1 ||
+		0 != (len(cycleErr.Path)-1)%4 {
+		t.Fatal(cycleErr)
+	}
+	// every node is visited at least once, before the cycle is detected
+	if visits < 6 {
+		t.Fatal(visits)
+	}
+}
+
+func TestWalk_selfCycle(t *testing.T) {
+	err := Walk(
+		1,
+		graphChildren(map[int][]int{1: {1}}),
+		func(node interface{}, depth int) error {
+			if depth > 1 {
+				t.Fatal(depth)
+			}
+			return nil
+		},
+	)
+	if err == nil || err.Error() != "cycle detected: hare (step 2) 1 revisited tortoise (step 1) 1, path: 1 -> 1" {
+		t.Fatal(err)
+	}
+}
+
+func TestWalk_visitError(t *testing.T) {
+	expected := errors.New("some error")
+	var visited []interface{}
+	err := Walk(
+		1,
+		graphChildren(map[int][]int{
+			1: {2, 4},
+			2: {3},
+			3: {2},
+		}),
+		func(node interface{}, depth int) error {
+			visited = append(visited, node)
+			if 3 == node {
+				return expected
+			}
+			return nil
+		},
+	)
+	if err != expected || fmt.Sprint(visited) != "[1 2 3]" {
+		t.Fatal(err, visited)
+	}
+}
+
+func TestWalk_skipChildren(t *testing.T) {
+	var visited []interface{}
+	err := Walk(
+		1,
+		graphChildren(map[int][]int{
+			1: {2, 4},
+			2: {3},
+			3: {2},
+			4: {5},
+		}),
+		func(node interface{}, depth int) error {
+			visited = append(visited, node)
+			if 2 == node {
+				return SkipChildren
+			}
+			return nil
+		},
+	)
+	if nil != err || fmt.Sprint(visited) != "[1 2 4 5]" {
+		t.Fatal(err, visited)
+	}
+	if err := Walk(1, graphChildren(nil), func(interface{}, int) error { return SkipChildren }); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestWalk_panic(t *testing.T) {
+	for _, tc := range []struct {
+		children func(node interface{}) []interface{}
+		visit    func(node interface{}, depth int) error
+		expected string
+	}{
+		{nil, func(interface{}, int) error { return nil }, "[Walk] children must be non-nil"},
+		{graphChildren(nil), nil, "[Walk] visit must be non-nil"},
+	} {
+		func() {
+			defer func() {
+				if r := fmt.Sprint(recover()); r != tc.expected {
+					t.Fatal(r)
+				}
+			}()
+			_ = Walk(nil, tc.children, tc.visit)
+		}()
+	}
+}
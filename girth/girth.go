@@ -0,0 +1,42 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package girth provides computation of the girth of a directed graph, the length of it's shortest cycle, along
+// with a cycle that achieves it. See also the weighted package, for the minimum mean cycle, of a weighted graph.
+package girth
+
+import (
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+// Girth returns one of the shortest cycles in g, formatted like floyds.BranchingDetector.Cycle, starting and ending
+// with the same node, e.g. `[a b c a]`, along with it's length (the number of edges, which is the girth), and true,
+// or false if g is acyclic. Each of the cyclic strongly connected components of g is searched, from each of their
+// nodes, in order (see also graph.Graph), using a breadth first search, meaning it takes O(n(n + e)) time, in the
+// worst case, and the result is deterministic. Note that an edge from a node to itself is a cycle of length one.
+func Girth(g graph.Graph) ([]interface{}, int, bool) {
+	d := digraph.New(g)
+	shortest := d.ShortestCycle(nil)
+	if nil == shortest {
+		return nil, 0, false
+	}
+	cycle := make([]interface{}, len(shortest))
+	for i, v := range shortest {
+		cycle[i] = d.Nodes[v]
+	}
+	return cycle, len(cycle) - 1, true
+}
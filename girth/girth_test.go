@@ -0,0 +1,109 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package girth
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/johnson"
+)
+
+func TestGirth(t *testing.T) {
+	var g graph.Adjacency
+	for _, e := range [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"d", "a"}, {"c", "e"}, {"e", "b"}} {
+		g.AddEdge(e[0], e[1])
+	}
+	if cycle, length, ok := Girth(&g); false == ok || 3 != length || fmt.Sprint(cycle) != "[b c e b]" {
+		t.Fatal(cycle, length, ok)
+	}
+	g.AddEdge("d", "d")
+	if cycle, length, ok := Girth(&g); false == ok || 1 != length || fmt.Sprint(cycle) != "[d d]" {
+		t.Fatal(cycle, length, ok)
+	}
+}
+
+func TestGirth_acyclic(t *testing.T) {
+	var g graph.Adjacency
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 3)
+	g.AddEdge(1, 3)
+	if cycle, length, ok := Girth(&g); true == ok || nil != cycle || 0 != length {
+		t.Fatal(cycle, length, ok)
+	}
+	if _, _, ok := Girth(&graph.Adjacency{}); true == ok {
+		t.Fatal()
+	}
+}
+
+func TestGirth_random(t *testing.T) {
+	rand.Seed(12093)
+	errStop := errors.New("stop")
+	for x := 0; x < 300; x++ {
+		var (
+			g graph.Adjacency
+			n = 1 + rand.Intn(10)
+		)
+		for i := 0; i < n; i++ {
+			g.AddNode(i)
+		}
+		for i := rand.Intn(2 * n); i > 0; i-- {
+			g.AddEdge(rand.Intn(n), rand.Intn(n))
+		}
+		// the shortest of every circuit
+		expected := -1
+		_ = johnson.Circuits(&g, func(cycle []interface{}) error {
+			if -1 == expected || len(cycle)-1 < expected {
+				expected = len(cycle) - 1
+			}
+			return nil
+		})
+		cycle, length, ok := Girth(&g)
+		if ok != (-1 != expected) || (ok && length != expected) {
+			t.Fatal(x, cycle, length, expected)
+		}
+		if false == ok {
+			continue
+		}
+		// it must actually be a circuit
+		var found bool
+		_ = johnson.Circuits(&g, func(c []interface{}) error {
+			if len(c) == len(cycle) {
+				for offset := 0; offset < length; offset++ {
+					match := true
+					for i := 0; i < length; i++ {
+						if c[(i+offset)%length] != cycle[i] {
+							match = false
+							break
+						}
+					}
+					if match {
+						found = true
+						return errStop
+					}
+				}
+			}
+			return nil
+		})
+		if false == found {
+			t.Fatal(x, cycle)
+		}
+	}
+}
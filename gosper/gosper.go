@@ -0,0 +1,228 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package gosper provides means of detecting cycles, using Gosper's algorithm, with an API that mirrors the Detector
+// from the floyds package, so that the two may be used interchangeably.
+package gosper
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+)
+
+/*
+The Detector struct is a cycle detector using Gosper's algorithm, which keeps a table of O(log n) previous steps, where
+n is the number of steps taken, comparing every new step against all of them. Unlike floyds.Detector, there is no
+tortoise that needs to be stepped, so next is never called while detecting a cycle using the `Hare` method, and the
+cycle will be detected no later than after the first repeated step that is still in the table, which happens at or
+before mu + 2 * lambda steps, at the cost of O(log n) comparisons per step.
+
+Gosper's algorithm is inexact, in that it never re-evaluates next, and so it cannot find the start (mu) of the cycle
+exactly, only a lower and upper bound, which are at most lambda apart. It is typically described as also providing
+bounds on the length (lambda) of the cycle, since the table only records the steps, but this implementation records
+the index of each step alongside it, which makes the length exact.
+
+This implementation treats the current state as immutable, like floyds.Detector, which means that the table is
+copied each time a step is stored in it (every step). If you call any methods on something that was not constructed
+using the constructor, a panic will occur.
+
+Usage:
+
+	- Create with `NewDetector`, providing start and next, optionally providing compare (defaults to identity).
+	- Increment with either `Hare` OR `Next`, using `Ok` check for cycles, and passing down the new structs.
+	- If you are using `Next` method, you will want to indicate done (out of bounds), by make it return false
+		from the next method, or you may not get the results you expect.
+	- Once `Ok` returns false, call `Result` for the length of the cycle (lambda) and the bounds on it's start (mu).
+
+Gosper's algorithm, for reference (HAKMEM item 132, adjusted to be zero indexed).
+
+https://en.wikipedia.org/wiki/Cycle_detection
+
+	table = []
+	n = 0
+	x = x0
+	while True:
+		for stored in table:
+			if stored == x:
+				return
+		k = trailing_zeros(n + 1)
+		if k == len(table):
+			table.append(x)
+		else:
+			table[k] = x
+		n += 1
+		x = f(x)
+*/
+type Detector struct {
+	next      func(v interface{}) (step interface{}, ok bool)
+	compare   func(stored, step interface{}) bool
+	table     []entry
+	hare      interface{}
+	ok        bool
+	done      bool
+	hareCount int
+	match     int
+}
+
+// The entry struct models a step stored in the table, along with it's index.
+type entry struct {
+	step  interface{}
+	index int
+}
+
+// NewDetector constructs a new Detector struct, and must provide the start step, and function to resolve the next
+// step (from the previous step each time), and may optionally include a custom comparison method, which defaults to
+// floyds.CompareIdentity, and will be called with a step stored in the table, and the step just taken, in that order.
+func NewDetector(start interface{}, next func(v interface{}) (interface{}, bool), compare func(stored, step interface{}) bool) Detector {
+	if nil == next {
+		panic(errors.New("[NewDetector] next must be non-nil"))
+	}
+	if nil == compare {
+		compare = floyds.CompareIdentity
+	}
+	return Detector{next, compare, []entry{{start, 0}}, start, true, false, 0, 0}
+}
+
+func (f Detector) validate() {
+	if nil == f.next || nil == f.compare {
+		panic(errors.New("[Detector.validate] nil property encountered, use the constructor NewDetector"))
+	}
+}
+
+// Ok will return true only if there has been no cycle detected so far.
+func (f Detector) Ok() bool {
+	f.validate()
+	return f.ok
+}
+
+// SetNext returns a new Detector that is the same as the receiver, but with the provided next function.
+func (f Detector) SetNext(next func(v interface{}) (step interface{}, ok bool)) Detector {
+	f.validate()
+	if nil == next {
+		panic(errors.New("[Detector.SetNext] you cannot set a nil next"))
+	}
+	f.next = next
+	return f
+}
+
+// SetCompare returns a new Detector that is the same as the receiver, but with the provided compare function.
+func (f Detector) SetCompare(compare func(stored, step interface{}) bool) Detector {
+	f.validate()
+	if nil == compare {
+		panic(errors.New("[Detector.SetCompare] you cannot set a nil compare"))
+	}
+	f.compare = compare
+	return f
+}
+
+// Hare returns a new Detector with the hare moved forward one step (which must be provided), comparing it against
+// every step in the table, and storing it in the table, if it didn't match. The name is for parity with
+// floyds.Detector, since there is only one stepper. Unlike floyds.Detector, next is never called.
+func (f Detector) Hare(step interface{}) Detector {
+	f.validate()
+	if false == f.ok || true == f.done {
+		return f
+	}
+
+	f.hare = step
+	f.hareCount++
+
+	for _, e := range f.table {
+		if true == f.compare(e.step, step) {
+			f.ok = false
+			f.match = e.index
+			return f
+		}
+	}
+
+	// the step is stored in the slot given by the number of trailing zeros of n + 1, the table is copied since it
+	// may be shared with other detectors
+	k := bits.TrailingZeros(uint(f.hareCount + 1))
+	table := make([]entry, len(f.table), len(f.table)+1)
+	copy(table, f.table)
+	if k == len(table) {
+		table = append(table, entry{})
+	}
+	table[k] = entry{step, f.hareCount}
+	f.table = table
+
+	return f
+}
+
+// Next returns a new Detector with the hare moved forward one step, using next, which is otherwise the same as
+// calling `Hare` with the step.
+func (f Detector) Next() Detector {
+	f.validate()
+	if false == f.ok || true == f.done {
+		return f
+	}
+	step, ok := f.next(f.hare)
+	if false == ok {
+		// no change, exit immediately - there was no cycle
+		f.done = true
+		return f
+	}
+	return f.Hare(step)
+}
+
+// HareCount gets the number of steps that hare has taken, since the start.
+func (f Detector) HareCount() int {
+	f.validate()
+	return f.hareCount
+}
+
+// Done will return true if any calls to next have returned a false ok value.
+func (f Detector) Done() bool {
+	f.validate()
+	return f.done
+}
+
+// Result models the length of a cycle, and the bounds on it's start, as found by Detector.Result.
+type Result struct {
+	// Lambda is the length of the cycle, also known as the period.
+	Lambda int
+	// MuLower is the lower bound (inclusive) of the index of the first step that is part of the cycle, where the
+	// start step has index 0.
+	MuLower int
+	// MuUpper is the upper bound (inclusive) of the index of the first step that is part of the cycle, which will
+	// be less than MuLower + Lambda.
+	MuUpper int
+	// Meeting is the step the hare was on, when it matched a step in the table.
+	Meeting interface{}
+}
+
+// Result returns the length (lambda) and bounds on the start (mu) of the cycle, without calling next, the ok return
+// value will be false if no cycle has been detected yet.
+func (f Detector) Result() (result Result, ok bool) {
+	f.validate()
+	if true == f.ok {
+		return Result{}, false
+	}
+
+	// since the cycle was detected as soon as possible, the matched step must be exactly one cycle behind
+	result.Lambda = f.hareCount - f.match
+	result.Meeting = f.hare
+
+	// the matched step is part of the cycle, so it's an upper bound, and the lower bound comes from the last step
+	// before it, that would have still been in the table, one cycle later (when it must not have matched), which is
+	// the last step with index + 1 divisible by the largest power of two that's not greater than lambda
+	result.MuUpper = f.match
+	result.MuLower = f.match &^ (1<<uint(bits.Len(uint(result.Lambda))-1) - 1)
+
+	return result, true
+}
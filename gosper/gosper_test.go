@@ -0,0 +1,275 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package gosper
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// rhoNext returns a next function for the sequence 0, 1, 2, ..., which returns to mu after mu + lambda - 1.
+func rhoNext(mu, lambda int) func(v interface{}) (interface{}, bool) {
+	return func(v interface{}) (interface{}, bool) {
+		n := v.(int) + 1
+		if n >= mu+lambda {
+			n = mu
+		}
+		return n, true
+	}
+}
+
+func expectPanic(t *testing.T, message string, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if err, ok := recover().(error); false == ok || nil == err || message != err.Error() {
+			t.Fatal(err)
+		}
+	}()
+	fn()
+	t.Fatal()
+}
+
+// sameState returns true if the non-func fields of a and b are equal, and they share the same table.
+func sameState(a, b Detector) bool {
+	return len(a.table) == len(b.table) && (0 == len(a.table) || &a.table[0] == &b.table[0]) && a.hare == b.hare &&
+		a.ok == b.ok && a.done == b.done && a.hareCount == b.hareCount && a.match == b.match
+}
+
+func TestDetector_Hare(t *testing.T) {
+	for mu := 0; mu < 40; mu++ {
+		for lambda := 1; lambda < 40; lambda++ {
+			next := rhoNext(mu, lambda)
+			f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+				t.Fatal()
+				return nil, false
+			}, nil)
+			step := interface{}(0)
+			for x := 0; f.Ok(); x++ {
+				if x >= mu+2*lambda {
+					t.Fatal(mu, lambda)
+				}
+				if f.HareCount() != x || len(f.table) != bits.Len(uint(x+1)) {
+					t.Fatal(mu, lambda, x, len(f.table))
+				}
+				if _, ok := f.Result(); true == ok {
+					t.Fatal(mu, lambda)
+				}
+				step, _ = next(step)
+				f = f.Hare(step)
+			}
+			result, ok := f.Result()
+			if false == ok || lambda != result.Lambda || f.hare != result.Meeting ||
+				result.MuLower > mu || result.MuUpper < mu || result.MuUpper-result.MuLower >= lambda {
+				t.Fatal(mu, lambda, ok, result)
+			}
+		}
+	}
+}
+
+func TestDetector_Next(t *testing.T) {
+	for mu := 0; mu < 40; mu++ {
+		for lambda := 1; lambda < 40; lambda++ {
+			calls := 0
+			next := rhoNext(mu, lambda)
+			f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+				calls++
+				return next(v)
+			}, nil)
+			for f.Ok() {
+				f = f.Next()
+			}
+			if calls != f.HareCount() {
+				t.Fatal(mu, lambda, calls, f.HareCount())
+			}
+			result, ok := f.Result()
+			if false == ok || lambda != result.Lambda || f.hare != result.Meeting ||
+				result.MuLower > mu || result.MuUpper < mu || result.MuUpper-result.MuLower >= lambda {
+				t.Fatal(mu, lambda, ok, result)
+			}
+		}
+	}
+}
+
+func TestDetector_Hare_immutable(t *testing.T) {
+	f := NewDetector(0, rhoNext(0, 1), nil)
+	for x := 1; x < 20; x++ {
+		f = f.Hare(x)
+	}
+	table := append([]entry(nil), f.table...)
+	a, b := f.Hare(100), f.Hare(200)
+	for x := 101; x < 140; x++ {
+		a = a.Hare(x)
+		b = b.Hare(x + 100)
+	}
+	if false == a.Ok() || false == b.Ok() || false == f.Ok() {
+		t.Fatal()
+	}
+	for i := range table {
+		if table[i] != f.table[i] {
+			t.Fatal(i, table[i], f.table[i])
+		}
+	}
+	if a.Hare(139).Ok() || false == b.Hare(139).Ok() || b.Hare(239).Ok() {
+		t.Fatal()
+	}
+}
+
+func TestDetector_Next_noCycle(t *testing.T) {
+	next := func(v interface{}) (interface{}, bool) {
+		if 10 == v {
+			return nil, false
+		}
+		return v.(int) + 1, true
+	}
+	f := NewDetector(0, next, nil)
+	for x := 0; x < 100 && false == f.Done(); x++ {
+		f = f.Next()
+	}
+	if false == f.Ok() || false == f.Done() || 10 != f.HareCount() || 10 != f.hare {
+		t.Fatal(f)
+	}
+	if result, ok := f.Result(); true == ok || (Result{}) != result {
+		t.Fatal(result)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestDetector_compare(t *testing.T) {
+	list := []string{"a", "b", "c", "d", "b", "c", "d", "b", "c", "d", "b", "c", "d"}
+	next := func(v interface{}) (interface{}, bool) {
+		return v.(int) + 1, true
+	}
+	compare := func(stored, step interface{}) bool {
+		if stored.(int) >= step.(int) {
+			t.Fatal(stored, step)
+		}
+		return list[stored.(int)] == list[step.(int)]
+	}
+	f := NewDetector(0, next, compare)
+	for f.Ok() {
+		f = f.Next()
+	}
+	result, ok := f.Result()
+	if false == ok || 3 != result.Lambda || 4 != f.HareCount() || 0 != result.MuLower || 1 != result.MuUpper {
+		t.Fatal(ok, result)
+	}
+}
+
+func TestDetector_notOk(t *testing.T) {
+	f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+		t.Fatal()
+		return nil, false
+	}, func(stored, step interface{}) bool {
+		t.Fatal()
+		return false
+	})
+	f.ok = false
+	if f2 := f.Hare(1); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestDetector_done(t *testing.T) {
+	f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+		t.Fatal()
+		return nil, false
+	}, func(stored, step interface{}) bool {
+		t.Fatal()
+		return false
+	})
+	f.done = true
+	if f2 := f.Hare(1); false == sameState(f, f2) || false == f2.Done() {
+		t.Fatal(f2)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	next := func(v interface{}) (interface{}, bool) {
+		return "next", true
+	}
+	f := NewDetector(23, next, nil)
+	if nil == f.next || nil == f.compare || 1 != len(f.table) || (entry{23, 0}) != f.table[0] || 23 != f.hare ||
+		false == f.ok || true == f.done || 0 != f.hareCount || 0 != f.match {
+		t.Fatal(f)
+	}
+	if false == f.compare(2323, 2323) || true == f.compare(2323, 2324) || true == f.compare("1", 1) {
+		t.Fatal()
+	}
+}
+
+func TestNewDetector_panic(t *testing.T) {
+	expectPanic(t, "[NewDetector] next must be non-nil", func() {
+		NewDetector(nil, nil, nil)
+	})
+}
+
+func TestDetector_SetNext(t *testing.T) {
+	f := NewDetector(1, rhoNext(0, 1), nil)
+	f = f.SetNext(func(a interface{}) (interface{}, bool) {
+		if 12 != a {
+			t.Fatal()
+		}
+		return "aa", true
+	})
+	if n, ok := f.next(12); false == ok || "aa" != n {
+		t.Fatal()
+	}
+	expectPanic(t, "[Detector.SetNext] you cannot set a nil next", func() {
+		f.SetNext(nil)
+	})
+}
+
+func TestDetector_SetCompare(t *testing.T) {
+	f := NewDetector(1, rhoNext(0, 1), nil)
+	f = f.SetCompare(func(a, b interface{}) bool {
+		if 1 != a || 2 != b {
+			t.Fatal()
+		}
+		return true
+	})
+	if true != f.compare(1, 2) {
+		t.Fatal()
+	}
+	expectPanic(t, "[Detector.SetCompare] you cannot set a nil compare", func() {
+		f.SetCompare(nil)
+	})
+}
+
+func TestDetector_validate_panic(t *testing.T) {
+	const message = "[Detector.validate] nil property encountered, use the constructor NewDetector"
+	for _, fn := range []func(f Detector){
+		func(f Detector) { f.Ok() },
+		func(f Detector) { f.Done() },
+		func(f Detector) { f.HareCount() },
+		func(f Detector) { f.Hare(nil) },
+		func(f Detector) { f.Next() },
+		func(f Detector) { f.Result() },
+		func(f Detector) { f.SetNext(nil) },
+		func(f Detector) { f.SetCompare(nil) },
+	} {
+		expectPanic(t, message, func() { fn(Detector{}) })
+	}
+}
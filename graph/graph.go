@@ -0,0 +1,75 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package graph provides the model for directed graphs, shared by the packages in this module that analyse whole
+// graphs, rather than following a single path of steps, like the floyds package.
+package graph
+
+type (
+	// Graph models a directed graph, where each node must be comparable (it's used as a map key), and nodes are
+	// always processed in the order they are returned, so that the results are deterministic (if the graph is).
+	// Any nodes returned by Successors, but not by Nodes, will be treated as if they were returned by Nodes, after
+	// all the others.
+	Graph interface {
+		// Nodes returns every node in the graph.
+		Nodes() []interface{}
+		// Successors returns every node that the given node has an edge to, note that duplicates are allowed, but
+		// may result in duplicate output, depending on the algorithm.
+		Successors(node interface{}) []interface{}
+	}
+
+	// Edge models a directed edge, from one node to another.
+	Edge struct {
+		From, To interface{}
+	}
+
+	// Adjacency is a Graph, built by adding nodes and edges, which preserves the order they were first added. The
+	// zero value is an empty graph, ready to use, note that it's not safe to use concurrently, while it's modified.
+	Adjacency struct {
+		nodes      []interface{}
+		successors map[interface{}][]interface{}
+	}
+)
+
+// AddNode adds node to the graph, if it isn't already present.
+func (g *Adjacency) AddNode(node interface{}) {
+	if nil == g.successors {
+		g.successors = make(map[interface{}][]interface{})
+	}
+	if _, ok := g.successors[node]; false == ok {
+		g.nodes = append(g.nodes, node)
+		g.successors[node] = nil
+	}
+}
+
+// AddEdge adds an edge from one node to another, adding either node, if it isn't already present, note that
+// duplicate edges are not removed.
+func (g *Adjacency) AddEdge(from, to interface{}) {
+	g.AddNode(from)
+	g.AddNode(to)
+	g.successors[from] = append(g.successors[from], to)
+}
+
+// Nodes implements Graph, returning the nodes in the order they were first added, and must not be modified.
+func (g *Adjacency) Nodes() []interface{} {
+	return g.nodes
+}
+
+// Successors implements Graph, returning the successors of node, in the order the edges were added, and must not be
+// modified.
+func (g *Adjacency) Successors(node interface{}) []interface{} {
+	return g.successors[node]
+}
@@ -0,0 +1,55 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAdjacency_zero(t *testing.T) {
+	var g Adjacency
+	if nil != g.Nodes() || nil != g.Successors(1) {
+		t.Fatal(g)
+	}
+	var _ Graph = &g
+}
+
+func TestAdjacency(t *testing.T) {
+	var g Adjacency
+	g.AddEdge("b", "a")
+	g.AddNode("c")
+	g.AddNode("a")
+	g.AddEdge("a", "c")
+	g.AddEdge("b", "c")
+	g.AddEdge("b", "a")
+	g.AddEdge("d", "d")
+	if s := fmt.Sprint(g.Nodes()); s != "[b a c d]" {
+		t.Fatal(s)
+	}
+	for _, tc := range [][2]string{
+		{"a", "[c]"},
+		{"b", "[a c a]"},
+		{"c", "[]"},
+		{"d", "[d]"},
+		{"e", "[]"},
+	} {
+		if s := fmt.Sprint(g.Successors(tc[0])); s != tc[1] {
+			t.Error(tc[0], s)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package digraph provides an indexed snapshot of a graph.Graph, where each node is identified by it's index, and the
+// shared graph algorithms that operate on it, used by the packages that analyse whole graphs.
+package digraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+)
+
+// Graph is a snapshot of a graph.Graph, where each node is identified by it's index in Nodes.
+type Graph struct {
+	// Nodes contains every node, in the order they were returned by Nodes, followed by any that were only returned
+	// by Successors, in the order they were first found.
+	Nodes []interface{}
+	// Index maps each node to it's index in Nodes.
+	Index map[interface{}]int
+	// Successors contains the indexes of the successors of each node, by index, including any duplicates.
+	Successors [][]int
+}
+
+// New returns a snapshot of g, calling Nodes once, and Successors exactly once for each node.
+func New(g graph.Graph) Graph {
+	nodes := g.Nodes()
+	d := Graph{
+		Nodes: make([]interface{}, 0, len(nodes)),
+		Index: make(map[interface{}]int, len(nodes)),
+	}
+	for _, node := range nodes {
+		d.add(node)
+	}
+	// note that the length of d.Nodes may increase, as successors are added
+	for i := 0; i < len(d.Nodes); i++ {
+		successors := g.Successors(d.Nodes[i])
+		indexes := make([]int, len(successors))
+		for j, node := range successors {
+			indexes[j] = d.add(node)
+		}
+		d.Successors = append(d.Successors, indexes)
+	}
+	return d
+}
+
+// The add method returns the index of node, adding it if it isn't already present.
+func (d *Graph) add(node interface{}) int {
+	if i, ok := d.Index[node]; true == ok {
+		return i
+	}
+	i := len(d.Nodes)
+	d.Nodes = append(d.Nodes, node)
+	d.Index[node] = i
+	return i
+}
+
+// Components returns the strongly connected components of the subgraph induced by the nodes that keep returns true
+// for (or every node, if keep is nil), using an iterative implementation of Tarjan's algorithm, so it's safe to use
+// with deep graphs. The components are returned in reverse topological order (every edge between two components is
+// from a later component to an earlier one), with the nodes of each component in the order they were discovered,
+// and the search is started from each node, in order, meaning the result is deterministic.
+func (d Graph) Components(keep func(node int) bool) [][]int {
+	type frame struct {
+		node int
+		next int
+	}
+	var (
+		n          = len(d.Nodes)
+		index      = make([]int, n) // zero means unvisited, otherwise the discovery order, from one
+		low        = make([]int, n)
+		onStack    = make([]bool, n)
+		stack      []int
+		calls      []frame
+		counter    int
+		components [][]int
+	)
+	push := func(node int) {
+		counter++
+		index[node] = counter
+		low[node] = counter
+		onStack[node] = true
+		stack = append(stack, node)
+		calls = append(calls, frame{node: node})
+	}
+	for root := 0; root < n; root++ {
+		if 0 != index[root] || (nil != keep && false == keep(root)) {
+			continue
+		}
+		push(root)
+		for 0 != len(calls) {
+			top := &calls[len(calls)-1]
+			v := top.node
+			if top.next < len(d.Successors[v]) {
+				w := d.Successors[v][top.next]
+				top.next++
+				if nil != keep && false == keep(w) {
+					continue
+				}
+				if 0 == index[w] {
+					push(w)
+				} else if onStack[w] && index[w] < low[v] {
+					low[v] = index[w]
+				}
+				continue
+			}
+			calls = calls[:len(calls)-1]
+			if 0 != len(calls) {
+				if p := calls[len(calls)-1].node; low[v] < low[p] {
+					low[p] = low[v]
+				}
+			}
+			if low[v] == index[v] {
+				i := len(stack) - 1
+				for stack[i] != v {
+					i--
+				}
+				component := make([]int, len(stack)-i)
+				copy(component, stack[i:])
+				for _, w := range component {
+					onStack[w] = false
+				}
+				stack = stack[:i]
+				components = append(components, component)
+			}
+		}
+	}
+	return components
+}
+
+// HasSelfLoop returns true if node has an edge to itself.
+func (d Graph) HasSelfLoop(node int) bool {
+	for _, w := range d.Successors[node] {
+		if w == node {
+			return true
+		}
+	}
+	return false
+}
+
+// ShortestCycle returns one of the shortest cycles, within the subgraph induced by the nodes that keep returns true
+// for (or every node, if keep is nil), starting and ending with the same node, or nil if there are none. Each of the
+// cyclic strongly connected components is searched, from each of their nodes, in order, using a breadth first search,
+// meaning it takes O(n(n + e)) time, in the worst case, and the result is deterministic.
+func (d Graph) ShortestCycle(keep func(node int) bool) []int {
+	var shortest []int
+	for _, component := range d.Components(keep) {
+		if 1 == len(component) {
+			if d.HasSelfLoop(component[0]) {
+				// can't be any shorter than a self-loop
+				return []int{component[0], component[0]}
+			}
+			continue
+		}
+		member := make(map[int]bool, len(component))
+		for _, v := range component {
+			member[v] = true
+		}
+		for _, v := range component {
+			if cycle := d.shortestCycleFrom(member, v); nil != cycle && (nil == shortest || len(cycle) < len(shortest)) {
+				shortest = cycle
+				if 2 == len(shortest) {
+					return shortest
+				}
+			}
+		}
+	}
+	return shortest
+}
+
+// The shortestCycleFrom method returns the shortest cycle through start, within member, starting and ending with
+// start, or nil if there is none.
+func (d Graph) shortestCycleFrom(member map[int]bool, start int) []int {
+	parent := map[int]int{start: -1}
+	queue := []int{start}
+	for 0 != len(queue) {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range d.Successors[v] {
+			if w == start {
+				var cycle []int
+				for u := v; -1 != u; u = parent[u] {
+					cycle = append(cycle, u)
+				}
+				for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+					cycle[i], cycle[j] = cycle[j], cycle[i]
+				}
+				return append(cycle, start)
+			}
+			if _, ok := parent[w]; false == ok && true == member[w] {
+				parent[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return nil
+}
+
+// FormatCycle returns prefix, followed by each node of cycle, formatted using `%v`, and joined by separator, e.g.
+// `cycle detected: a -> b -> a`, and is shared by the error types that describe a cycle.
+func FormatCycle(prefix, separator string, cycle []interface{}) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i, v := range cycle {
+		if 0 != i {
+			b.WriteString(separator)
+		}
+		fmt.Fprintf(&b, "%v", v)
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package digraph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+)
+
+type testGraph map[interface{}][]interface{}
+
+func (g testGraph) Nodes() []interface{} {
+	// only the first node, the rest must be found via successors
+	return []interface{}{"a"}
+}
+
+func (g testGraph) Successors(node interface{}) []interface{} {
+	return g[node]
+}
+
+func TestNew(t *testing.T) {
+	var calls int
+	g := testGraph{
+		"a": {"b", "c", "b"},
+		"c": {"d", "a"},
+	}
+	d := New(countingGraph{g, &calls})
+	if s := fmt.Sprint(d.Nodes, d.Successors); s != "[a b c d] [[1 2 1] [] [3 0] []]" {
+		t.Fatal(s)
+	}
+	if 4 != len(d.Index) || 2 != d.Index["c"] || 4 != calls {
+		t.Fatal(d.Index, calls)
+	}
+}
+
+type countingGraph struct {
+	graph.Graph
+	calls *int
+}
+
+func (g countingGraph) Successors(node interface{}) []interface{} {
+	*g.calls++
+	return g.Graph.Successors(node)
+}
+
+func TestGraph_Components(t *testing.T) {
+	var g graph.Adjacency
+	for _, e := range [][2]int{
+		{0, 1}, {1, 2}, {2, 0}, {2, 3}, {3, 4}, {4, 5}, {5, 3}, {6, 5}, {6, 7}, {7, 6}, {8, 8},
+	} {
+		g.AddEdge(e[0], e[1])
+	}
+	d := New(&g)
+	if s := fmt.Sprint(d.Components(nil)); s != "[[3 4 5] [0 1 2] [6 7] [8]]" {
+		t.Fatal(s)
+	}
+	// without 1 and 4, the first two components are broken up
+	keep := func(node int) bool { return 1 != node && 4 != node }
+	if s := fmt.Sprint(d.Components(keep)); s != "[[0] [3] [2] [5] [6 7] [8]]" {
+		t.Fatal(s)
+	}
+	if false == d.HasSelfLoop(8) || true == d.HasSelfLoop(0) {
+		t.Fatal()
+	}
+}
+
+func TestGraph_Components_deep(t *testing.T) {
+	// a single cycle, of a million nodes, which would overflow a recursive implementation (in other languages)
+	const n = 1000000
+	d := Graph{Nodes: make([]interface{}, n), Successors: make([][]int, n)}
+	for i := range d.Successors {
+		d.Nodes[i] = i
+		d.Successors[i] = []int{(i + 1) % n}
+	}
+	if c := d.Components(nil); 1 != len(c) || n != len(c[0]) || 0 != c[0][0] || n-1 != c[0][n-1] {
+		t.Fatal(len(c))
+	}
+	// and as a chain
+	d.Successors[n-1] = nil
+	if c := d.Components(nil); n != len(c) || n-1 != c[0][0] || 0 != c[n-1][0] {
+		t.Fatal(len(c))
+	}
+}
+
+func TestGraph_ShortestCycle(t *testing.T) {
+	d := Graph{
+		Nodes:      []interface{}{0, 1, 2, 3, 4, 5, 6},
+		Successors: [][]int{{1, 2}, {3}, {0}, {0}, {5}, {6}, {4, 6}},
+	}
+	if s := fmt.Sprint(d.ShortestCycle(nil)); s != "[6 6]" {
+		t.Fatal(s)
+	}
+	if s := fmt.Sprint(d.ShortestCycle(func(node int) bool { return node < 6 })); s != "[0 2 0]" {
+		t.Fatal(s)
+	}
+	if s := fmt.Sprint(d.ShortestCycle(func(node int) bool { return 2 != node && node < 6 })); s != "[0 1 3 0]" {
+		t.Fatal(s)
+	}
+	if nil != d.ShortestCycle(func(node int) bool { return 0 != node && node < 6 }) {
+		t.Fatal()
+	}
+}
+
+func TestGraph_shortestCycleFrom(t *testing.T) {
+	d := Graph{Successors: [][]int{{1, 2}, {3}, {0}, {0}}}
+	member := map[int]bool{0: true, 1: true, 2: true, 3: true}
+	if s := fmt.Sprint(d.shortestCycleFrom(member, 0)); s != "[0 2 0]" {
+		t.Fatal(s)
+	}
+	if s := fmt.Sprint(d.shortestCycleFrom(member, 3)); s != "[3 0 1 3]" {
+		t.Fatal(s)
+	}
+	delete(member, 0)
+	if nil != d.shortestCycleFrom(member, 1) {
+		t.Fatal()
+	}
+}
+
+func TestFormatCycle(t *testing.T) {
+	if s := FormatCycle("cycle detected: ", " -> ", []interface{}{1, "b", 1}); s != "cycle detected: 1 -> b -> 1" {
+		t.Fatal(s)
+	}
+	if s := FormatCycle("prefix", ", ", nil); s != "prefix" {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package jsonrules provides the rules encoding/json uses to determine how values are encoded, via reflect, like
+// which struct fields are encoded, and the names of map keys, used by the packages that walk values like it does.
+package jsonrules
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type (
+	// Field models a struct field, as it would be encoded by encoding/json.
+	Field struct {
+		// Name is the key of the field, in the encoded object.
+		Name string
+		// Tagged is true if Name was provided by a json tag.
+		Tagged bool
+		// Index is the index sequence of the field, for reflect.Value.FieldByIndex, see also FieldByIndex.
+		Index []int
+		// Type is the type of the field, or it's element type, if it's an unnamed pointer type.
+		Type reflect.Type
+		// OmitEmpty is true if the field has the omitempty option, see also IsEmptyValue.
+		OmitEmpty bool
+		// OmitZero is true if the field has the omitzero option, see also IsZeroValue.
+		OmitZero bool
+		// Quoted is true if the field has the string option, which only applies to fields of scalar types.
+		Quoted bool
+	}
+
+	// The byIndex type sorts fields by their index sequence.
+	byIndex []Field
+)
+
+// The fieldCache var maps reflect.Type to []Field, for TypeFields.
+var fieldCache sync.Map
+
+func (x byIndex) Len() int { return len(x) }
+
+func (x byIndex) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+
+func (x byIndex) Less(i, j int) bool {
+	for k, xik := range x[i].Index {
+		if k >= len(x[j].Index) {
+			return false
+		}
+		if xik != x[j].Index[k] {
+			return xik < x[j].Index[k]
+		}
+	}
+	return len(x[i].Index) < len(x[j].Index)
+}
+
+// TypeFields returns the fields that encoding/json would encode, for the struct type t, in the same order, applying
+// the same rules, for json tags, and embedded structs. The result is cached, and must not be modified.
+func TypeFields(t reflect.Type) []Field {
+	if f, ok := fieldCache.Load(t); true == ok {
+		return f.([]Field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]Field)
+}
+
+// FieldByIndex returns the field of the struct v with the given index sequence, like reflect.Value.FieldByIndex,
+// but returns false, rather than panicking, if it would need to traverse a nil embedded pointer.
+func FieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		if reflect.Ptr == v.Kind() {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// The typeFields function implements TypeFields, and is a port of the equivalent function from encoding/json.
+func typeFields(t reflect.Type) []Field {
+	var (
+		current []Field
+		next    = []Field{{Type: t}}
+		// count and nextCount are the number of times each type has been encountered, at the current and next level
+		count     map[reflect.Type]int
+		nextCount = map[reflect.Type]int{}
+		visited   = map[reflect.Type]bool{}
+		fields    []Field
+	)
+
+	for 0 != len(next) {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if true == visited[f.Type] {
+				continue
+			}
+			visited[f.Type] = true
+
+			for i := 0; i < f.Type.NumField(); i++ {
+				sf := f.Type.Field(i)
+				if true == sf.Anonymous {
+					t := sf.Type
+					if reflect.Ptr == t.Kind() {
+						t = t.Elem()
+					}
+					if "" != sf.PkgPath && reflect.Struct != t.Kind() {
+						// ignore embedded fields of unexported non-struct types
+						continue
+					}
+					// embedded unexported structs are walked, for their exported fields
+				} else if "" != sf.PkgPath {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if "-" == tag {
+					continue
+				}
+				name, opts := parseTag(tag)
+				if false == isValidTag(name) {
+					name = ""
+				}
+				index := make([]int, len(f.Index)+1)
+				copy(index, f.Index)
+				index[len(f.Index)] = i
+
+				ft := sf.Type
+				if "" == ft.Name() && reflect.Ptr == ft.Kind() {
+					ft = ft.Elem()
+				}
+
+				if "" != name || false == sf.Anonymous || reflect.Struct != ft.Kind() {
+					tagged := "" != name
+					if "" == name {
+						name = sf.Name
+					}
+					fields = append(fields, Field{
+						Name:      name,
+						Tagged:    tagged,
+						Index:     index,
+						Type:      ft,
+						OmitEmpty: hasOption(opts, "omitempty"),
+						OmitZero:  hasOption(opts, "omitzero"),
+						Quoted:    hasOption(opts, "string") && isQuotable(ft),
+					})
+					if count[f.Type] > 1 {
+						// multiple instances at the same level annihilate each other, this ensures there's a duplicate
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				// an untagged embedded struct, to explore at the next level
+				nextCount[ft]++
+				if 1 == nextCount[ft] {
+					next = append(next, Field{Name: ft.Name(), Index: index, Type: ft})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		x := fields
+		if x[i].Name != x[j].Name {
+			return x[i].Name < x[j].Name
+		}
+		if len(x[i].Index) != len(x[j].Index) {
+			return len(x[i].Index) < len(x[j].Index)
+		}
+		if x[i].Tagged != x[j].Tagged {
+			return x[i].Tagged
+		}
+		return byIndex(x).Less(i, j)
+	})
+
+	// remove any fields hidden by the Go rules for embedded fields, except that json tags take precedence
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		fi := fields[i]
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].Name != fi.Name {
+				break
+			}
+		}
+		if 1 == advance {
+			out = append(out, fi)
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); true == ok {
+			out = append(out, dominant)
+		}
+	}
+
+	fields = out
+	sort.Sort(byIndex(fields))
+
+	return fields
+}
+
+// The dominantField function returns the field that dominates the others with the same name (which must be sorted),
+// if there is one, otherwise (if there is a conflict), none of them are encoded.
+func dominantField(fields []Field) (Field, bool) {
+	if len(fields) > 1 && len(fields[0].Index) == len(fields[1].Index) && fields[0].Tagged == fields[1].Tagged {
+		return Field{}, false
+	}
+	return fields[0], true
+}
+
+// The isQuotable function returns true if the string option applies to fields of type t.
+func isQuotable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
+// The parseTag function splits a json tag into it's name and options.
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); -1 != i {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// The hasOption function returns true if the comma-separated options contain name.
+func hasOption(opts, name string) bool {
+	for "" != opts {
+		var next string
+		if i := strings.Index(opts, ","); -1 != i {
+			opts, next = opts[:i], opts[i+1:]
+		}
+		if opts == name {
+			return true
+		}
+		opts = next
+	}
+	return false
+}
+
+// The isValidTag function returns true if the tag name may be used as a key, by encoding/json.
+func isValidTag(s string) bool {
+	if "" == s {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// backslash and quote chars are reserved, but otherwise any punctuation chars are allowed
+		case false == unicode.IsLetter(c) && false == unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package jsonrules
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	testFieldsA struct {
+		A int
+		B int `json:"b"`
+		testFieldsB
+		*testFieldsC
+		D int `json:"-"`
+		E int `json:"e,omitempty,string"`
+		g int
+	}
+
+	testFieldsB struct {
+		A int
+		H int
+		I int `json:"i"`
+	}
+
+	testFieldsC struct {
+		H int
+		I int `json:"i"`
+		J int
+		testFieldsD
+	}
+
+	testFieldsD struct {
+		J int
+		K int `json:"k,omitzero"`
+	}
+
+	testFieldsNamed struct {
+		testFieldsB `json:"named"`
+	}
+)
+
+// The jsonKeys function returns the top level keys encoded by encoding/json, for v, in order.
+func jsonKeys(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if nil != err {
+		t.Fatal(err)
+	}
+	var keys []string
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+	if _, err := dec.Token(); nil != err {
+		t.Fatal(err)
+	}
+	for dec.More() {
+		k, err := dec.Token()
+		if nil != err {
+			t.Fatal(err)
+		}
+		keys = append(keys, k.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); nil != err {
+			t.Fatal(err)
+		}
+	}
+	return fmt.Sprint(keys)
+}
+
+func TestTypeFields(t *testing.T) {
+	for _, v := range []interface{}{
+		testFieldsA{testFieldsC: &testFieldsC{testFieldsD: testFieldsD{K: 1}}, E: 1},
+		testFieldsB{},
+		testFieldsC{testFieldsD: testFieldsD{K: 1}},
+		testFieldsD{K: 1},
+		testFieldsNamed{},
+		struct{}{},
+	} {
+		var names []string
+		for _, f := range TypeFields(reflect.TypeOf(v)) {
+			names = append(names, f.Name)
+		}
+		if actual, expected := fmt.Sprint(names), jsonKeys(t, v); actual != expected {
+			t.Errorf("%T: %s != %s", v, actual, expected)
+		}
+	}
+	fields := typeFields(reflect.TypeOf(testFieldsA{}))
+	if s := fmt.Sprintf("%s %v %v %s %v %v %v", fields[3].Name, fields[3].Index, fields[3].OmitZero, fields[4].Name, fields[4].Index, fields[4].OmitEmpty, fields[4].Quoted); s != "k [3 3 1] true e [5] true true" {
+		t.Fatal(s, fields)
+	}
+	if true == fields[0].Quoted || false == fields[1].Tagged || true == fields[0].Tagged {
+		t.Fatal(fields)
+	}
+}
+
+func Test_hasOption(t *testing.T) {
+	if false == hasOption("omitempty", "omitempty") || false == hasOption("string,omitempty", "omitempty") ||
+		true == hasOption("", "omitempty") || true == hasOption("omitemptyx", "omitempty") ||
+		false == hasOption("a,,omitzero", "omitzero") {
+		t.Fatal()
+	}
+}
+
+func Test_isValidTag(t *testing.T) {
+	for tag, expected := range map[string]bool{
+		"":          false,
+		"a":         true,
+		"a-b c!":    true,
+		"bad\"name": false,
+		"bad\\name": false,
+		"日本":        true,
+	} {
+		if isValidTag(tag) != expected {
+			t.Error(tag)
+		}
+	}
+}
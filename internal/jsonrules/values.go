@@ -0,0 +1,128 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package jsonrules
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// MapEntry is a map key, resolved to it's name, and the corresponding value, see also MapEntries.
+type MapEntry struct {
+	Name  string
+	Value reflect.Value
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// IsOmitted returns true if the value v, of field, would be omitted by encoding/json, due to the omitempty or omitzero
+// options.
+func IsOmitted(field Field, v reflect.Value) bool {
+	return (true == field.OmitEmpty && IsEmptyValue(v)) || (true == field.OmitZero && IsZeroValue(v))
+}
+
+// IsMarshaler returns true if encoding/json would use a json.Marshaler or encoding.TextMarshaler, to
+// encode v, which may only use pointer receivers if v is addressable.
+func IsMarshaler(v reflect.Value) bool {
+	t := v.Type()
+	if t.Implements(marshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	if reflect.Ptr != t.Kind() && v.CanAddr() {
+		t = reflect.PtrTo(t)
+		return t.Implements(marshalerType) || t.Implements(textMarshalerType)
+	}
+	return false
+}
+
+// MapEntries resolves the keys of the map v to the names used by encoding/json, sorted like it, returning false if any
+// key cannot be resolved (encoding/json would fail).
+func MapEntries(v reflect.Value) ([]MapEntry, bool) {
+	entries := make([]MapEntry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		name, ok := ResolveKeyName(iter.Key())
+		if false == ok {
+			return nil, false
+		}
+		entries = append(entries, MapEntry{name, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, true
+}
+
+// ResolveKeyName resolves a map key to it's name, the same way as encoding/json.
+func ResolveKeyName(k reflect.Value) (string, bool) {
+	if reflect.String == k.Kind() {
+		return k.String(), true
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); true == ok {
+		if reflect.Ptr == k.Kind() && k.IsNil() {
+			return "", true
+		}
+		b, err := tm.MarshalText()
+		return string(b), nil == err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
+
+// IsEmptyValue implements the omitempty option, the same way as encoding/json.
+func IsEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return 0 == v.Len()
+	case reflect.Bool:
+		return false == v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return 0 == v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return 0 == v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return 0 == v.Float()
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// IsZeroValue implements the omitzero option, the same way as encoding/json, preferring any IsZero method.
+func IsZeroValue(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); true == ok {
+		if reflect.Ptr == v.Kind() && v.IsNil() {
+			return true
+		}
+		return z.IsZero()
+	}
+	if reflect.Ptr != v.Kind() && v.CanAddr() {
+		if z, ok := v.Addr().Interface().(interface{ IsZero() bool }); true == ok {
+			return z.IsZero()
+		}
+	}
+	return v.IsZero()
+}
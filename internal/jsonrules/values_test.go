@@ -0,0 +1,170 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package jsonrules
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	testZero struct {
+		Count int
+	}
+
+	testKey struct {
+		name string
+	}
+
+	testPtrMarshaler struct{}
+)
+
+func (testZero) IsZero() bool { return true }
+
+func (k testKey) MarshalText() ([]byte, error) { return []byte(k.name), nil }
+
+func (m *testPtrMarshaler) MarshalJSON() ([]byte, error) { return []byte(`null`), nil }
+
+func TestIsZeroValue(t *testing.T) {
+	var nilTime *time.Time
+	for _, tc := range []struct {
+		v        interface{}
+		expected bool
+	}{
+		{0, true},
+		{1, false},
+		{time.Time{}, true},
+		{time.Now(), false},
+		{nilTime, true},
+		{testZero{Count: 1}, true},
+		{struct{ A int }{}, true},
+		{struct{ A int }{1}, false},
+	} {
+		if actual := IsZeroValue(reflect.ValueOf(tc.v)); actual != tc.expected {
+			t.Error(tc.v, actual)
+		}
+	}
+}
+
+func TestIsEmptyValue(t *testing.T) {
+	for _, tc := range []struct {
+		v        interface{}
+		expected bool
+	}{
+		{0, true},
+		{uint(0), true},
+		{0.0, true},
+		{false, true},
+		{"", true},
+		{[]int{}, true},
+		{map[int]int{}, true},
+		{[0]int{}, true},
+		{(*int)(nil), true},
+		{1, false},
+		{uint(1), false},
+		{0.5, false},
+		{true, false},
+		{"a", false},
+		{[]int{1}, false},
+		{[1]int{}, false},
+		{new(int), false},
+		{struct{}{}, false},
+		{time.Time{}, false},
+	} {
+		if actual := IsEmptyValue(reflect.ValueOf(tc.v)); actual != tc.expected {
+			t.Error(tc.v, actual)
+		}
+	}
+}
+
+func TestIsOmitted(t *testing.T) {
+	zero, one := reflect.ValueOf(0), reflect.ValueOf(1)
+	if true == IsOmitted(Field{}, zero) || false == IsOmitted(Field{OmitEmpty: true}, zero) ||
+		false == IsOmitted(Field{OmitZero: true}, zero) || true == IsOmitted(Field{OmitEmpty: true, OmitZero: true}, one) {
+		t.Fatal()
+	}
+}
+
+func TestIsMarshaler(t *testing.T) {
+	v := reflect.ValueOf(&struct {
+		A testPtrMarshaler
+		B time.Time
+		C testKey
+		D int
+	}{}).Elem()
+	if false == IsMarshaler(v.Field(0)) || false == IsMarshaler(v.Field(1)) || false == IsMarshaler(v.Field(2)) ||
+		true == IsMarshaler(v.Field(3)) || true == IsMarshaler(reflect.ValueOf(testPtrMarshaler{})) ||
+		false == IsMarshaler(reflect.ValueOf(&testPtrMarshaler{})) {
+		t.Fatal()
+	}
+}
+
+func TestMapEntries(t *testing.T) {
+	entries, ok := MapEntries(reflect.ValueOf(map[testKey]int{{"b"}: 1, {"a"}: 2, {"c"}: 3}))
+	if false == ok || fmt.Sprintf("%s %s %s %d", entries[0].Name, entries[1].Name, entries[2].Name, entries[0].Value.Int()) != "a b c 2" {
+		t.Fatal(entries)
+	}
+	entries, ok = MapEntries(reflect.ValueOf(map[int]int{10: 1, 9: 2, -1: 3}))
+	if false == ok || fmt.Sprint(entries[0].Name, " ", entries[1].Name, " ", entries[2].Name) != "-1 10 9" {
+		t.Fatal(entries)
+	}
+	if _, ok := MapEntries(reflect.ValueOf(map[float64]int{1: 1})); false != ok {
+		t.Fatal()
+	}
+}
+
+func TestResolveKeyName(t *testing.T) {
+	type named string
+	for _, tc := range []struct {
+		k        interface{}
+		expected string
+	}{
+		{"a", "a"},
+		{named("b"), "b"},
+		{testKey{"c"}, "c"},
+		{(*testKey)(nil), ""},
+		{int8(-3), "-3"},
+		{uint16(4), "4"},
+	} {
+		if actual, ok := ResolveKeyName(reflect.ValueOf(tc.k)); false == ok || actual != tc.expected {
+			t.Error(tc.k, actual)
+		}
+	}
+	if _, ok := ResolveKeyName(reflect.ValueOf(1.5)); false != ok {
+		t.Fatal()
+	}
+}
+
+func TestFieldByIndex(t *testing.T) {
+	type inner struct{ A int }
+	type outer struct {
+		*inner
+		B int
+	}
+	v := reflect.ValueOf(outer{B: 2})
+	if _, ok := FieldByIndex(v, []int{0, 0}); false != ok {
+		t.Fatal()
+	}
+	if f, ok := FieldByIndex(v, []int{1}); false == ok || 2 != f.Int() {
+		t.Fatal()
+	}
+	if f, ok := FieldByIndex(reflect.ValueOf(outer{inner: &inner{3}}), []int{0, 0}); false == ok || 3 != f.Int() {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,133 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package ref provides the shared logic for identifying references (pointers, maps, and slices) within arbitrary Go
+// values, via reflect, used by the packages that walk such values.
+package ref
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Key identifies a reference, and is comparable, so it may be used as a map key, or with
+// floyds.NewExactBranchingDetector. The zero value is never returned by KeyOf, so it may be used as a placeholder.
+type Key struct {
+	// Type is the type of the reference, which distinguishes between references to the same address, e.g. a pointer
+	// to a struct, and a pointer to it's first field.
+	Type reflect.Type
+	// Ptr is the address the reference points to.
+	Ptr uintptr
+	// Len is the length of the slice, and is always zero for other kinds, since slices that share an underlying
+	// array, from the same offset, are only the same if they have the same length.
+	Len int
+}
+
+// Step is the type of each step passed to a floyds.BranchingDetector, created using NewExactBranchingDetector, with
+// StepKey, by the packages that walk values, where Path is where the reference was found, in whatever format the
+// package reports, which is not compared, so it may be recovered from the steps of any cycle.
+type Step struct {
+	Key  Key
+	Path string
+}
+
+// StepKey is the key func for a floyds.BranchingDetector of Step values, returning it's Key.
+func StepKey(s interface{}) interface{} {
+	return s.(Step).Key
+}
+
+// KeyOf returns the Key for v, and true, if v is a non-nil pointer, map, or slice (with a non-zero length), otherwise
+// it returns false.
+func KeyOf(v reflect.Value) (Key, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if v.IsNil() {
+			return Key{}, false
+		}
+		return Key{Type: v.Type(), Ptr: v.Pointer()}, true
+	case reflect.Slice:
+		if 0 == v.Len() {
+			return Key{}, false
+		}
+		return Key{Type: v.Type(), Ptr: v.Pointer(), Len: v.Len()}, true
+	default:
+		return Key{}, false
+	}
+}
+
+// Pointerless returns true if values of type t can never contain any references, or interfaces (which could contain
+// references), meaning there is no need to walk them.
+func Pointerless(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		return true
+	case reflect.Array:
+		return 0 == t.Len() || Pointerless(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if false == Pointerless(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// FormatKey formats a map key, for use within a path like `["a"]`, quoting strings, and otherwise using `%v`, which
+// (unlike calling Interface) works for keys obtained via unexported fields.
+func FormatKey(k reflect.Value) string {
+	if reflect.Interface == k.Kind() && false == k.IsNil() {
+		k = k.Elem()
+	}
+	if reflect.String == k.Kind() {
+		return strconv.Quote(k.String())
+	}
+	return fmt.Sprint(k)
+}
+
+// MapKeys returns the keys of the map v, sorted by their FormatKey representation, so that the order is stable.
+func MapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	formatted := make([]string, len(keys))
+	for i, k := range keys {
+		formatted[i] = FormatKey(k)
+	}
+	sort.Sort(keySorter{keys, formatted})
+	return keys
+}
+
+// The keySorter struct implements sort.Interface, for MapKeys.
+type keySorter struct {
+	keys      []reflect.Value
+	formatted []string
+}
+
+func (s keySorter) Len() int { return len(s.keys) }
+
+func (s keySorter) Less(i, j int) bool { return s.formatted[i] < s.formatted[j] }
+
+func (s keySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.formatted[i], s.formatted[j] = s.formatted[j], s.formatted[i]
+}
@@ -0,0 +1,142 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ref
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestStepKey(t *testing.T) {
+	k := Key{Type: reflect.TypeOf(new(int)), Ptr: 1}
+	if v := StepKey(Step{Key: k, Path: ".A"}); v != k {
+		t.Fatal(v)
+	}
+	if StepKey(Step{Key: k, Path: ".A"}) != StepKey(Step{Key: k, Path: ".B"}) {
+		t.Fatal("path compared")
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	type S struct{ A, B int }
+	var (
+		s     = &S{}
+		slice = []int{1, 2, 3}
+		m     = map[string]int{}
+	)
+	key := func(v interface{}) Key {
+		k, ok := KeyOf(reflect.ValueOf(v))
+		if false == ok {
+			t.Fatal(v)
+		}
+		return k
+	}
+	if key(s) != key(s) || key(s) == key(&s.A) || key(s) == key(&S{}) ||
+		key(slice) != key(slice) || key(slice) == key(slice[:2]) || key(slice[1:]) != key(slice[1:3]) ||
+		key(m) != key(m) || key(m) == key(map[string]int{}) ||
+		key(s) == (Key{}) {
+		t.Fatal()
+	}
+	for _, v := range []interface{}{
+		(*S)(nil),
+		[]int{},
+		[]int(nil),
+		map[string]int(nil),
+		S{},
+		[1]int{},
+		1,
+		"a",
+		func() {},
+		make(chan int),
+	} {
+		if k, ok := KeyOf(reflect.ValueOf(v)); false != ok || (Key{}) != k {
+			t.Fatal(v)
+		}
+	}
+	if _, ok := KeyOf(reflect.Value{}); false != ok {
+		t.Fatal()
+	}
+}
+
+func TestPointerless(t *testing.T) {
+	for _, tc := range []struct {
+		v        interface{}
+		expected bool
+	}{
+		{1, true},
+		{"a", true},
+		{1.5, true},
+		{[3]int{}, true},
+		{[0]*int{}, true},
+		{[1]*int{}, false},
+		{struct{ A, B int }{}, true},
+		{struct {
+			A int
+			B struct{ C string }
+		}{}, true},
+		{struct {
+			A int
+			b *int
+		}{}, false},
+		{struct{ A interface{} }{}, false},
+		{[]int{}, false},
+		{map[int]int{}, false},
+		{new(int), false},
+		{func() {}, false},
+		{make(chan int), false},
+	} {
+		if actual := Pointerless(reflect.TypeOf(tc.v)); actual != tc.expected {
+			t.Errorf("%T: expected %v", tc.v, tc.expected)
+		}
+	}
+}
+
+func TestFormatKey(t *testing.T) {
+	type s struct{ m map[interface{}]int }
+	v := reflect.ValueOf(s{map[interface{}]int{"a": 1}}).Field(0)
+	if k := FormatKey(v.MapKeys()[0]); `"a"` != k {
+		t.Fatal(k)
+	}
+	for _, tc := range []struct {
+		k        interface{}
+		expected string
+	}{
+		{"a\"b", `"a\"b"`},
+		{1, "1"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{struct{ A, B int }{1, 2}, "{1 2}"},
+	} {
+		if k := FormatKey(reflect.ValueOf(tc.k)); tc.expected != k {
+			t.Error(k)
+		}
+	}
+}
+
+func TestMapKeys(t *testing.T) {
+	var actual []string
+	for _, k := range MapKeys(reflect.ValueOf(map[interface{}]int{"b": 1, "a": 2, 3: 3, 1: 4, "c": 5})) {
+		actual = append(actual, FormatKey(k))
+	}
+	if s := fmt.Sprint(actual); s != `["a" "b" "c" 1 3]` {
+		t.Fatal(s)
+	}
+	if 0 != len(MapKeys(reflect.ValueOf(map[int]int(nil)))) {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,227 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package johnson provides Johnson's algorithm, for enumerating every elementary circuit (a cycle that visits each
+// node at most once) of a directed graph, in O((n + e)(c + 1)) time, where c is the number of circuits, which may be
+// exponential in the size of the graph, hence the circuits are streamed, with optional limits.
+package johnson
+
+import (
+	"errors"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+type (
+	// Options configures Circuits, the zero value being the default (unlimited).
+	Options struct {
+		// MaxCount limits the number of circuits that will be visited, and is unlimited if it's zero or negative.
+		MaxCount int
+		// MaxLength limits the length (the number of edges) of circuits that will be visited, with any longer
+		// circuits skipped, and is unlimited if it's zero or negative. Note that, while a limit will prevent
+		// exploring longer paths, it also reduces the effectiveness of the pruning that Johnson's algorithm relies on.
+		MaxLength int
+	}
+
+	// The search struct holds the state for searching for the circuits starting at a single node.
+	search struct {
+		options Options
+		adj     [][]int
+		nodes   []interface{}
+		start   int
+		member  []bool
+		blocked []bool
+		b       []map[int]struct{}
+		count   int
+		visit   func(cycle []interface{}) error
+	}
+)
+
+// The errMaxCount var is used internally, to stop the search, once MaxCount circuits have been visited.
+var errMaxCount = errors.New("max count")
+
+// Circuits is Options.Circuits, using the default options.
+func Circuits(g graph.Graph, visit func(cycle []interface{}) error) error {
+	return Options{}.Circuits(g, visit)
+}
+
+// Circuits calls visit with every elementary circuit of g, subject to MaxCount and MaxLength, stopping and returning
+// the first error that visit returns, if any. Each cycle is formatted like floyds.BranchingDetector.Cycle,
+// starting and ending with the same node, so a cycle of length n (edges) has n+1 nodes, e.g. `[a b c a]`, or `[a a]`
+// for an edge from a node to itself. Each cycle starts with it's earliest node, in the order nodes were returned by
+// g (see also graph.Graph), and the order cycles are visited in is deterministic. Duplicate edges are ignored, and
+// each cycle is a new slice, which visit may retain. Note that the implementation is iterative, rather than recursive,
+// so it's safe to use with very long cycles. A nil visit will cause a panic.
+func (o Options) Circuits(g graph.Graph, visit func(cycle []interface{}) error) error {
+	if nil == visit {
+		panic(errors.New("[Options.Circuits] visit must be non-nil"))
+	}
+	d := digraph.New(g)
+	n := len(d.Nodes)
+	s := search{
+		options: o,
+		adj:     make([][]int, n),
+		nodes:   d.Nodes,
+		member:  make([]bool, n),
+		blocked: make([]bool, n),
+		b:       make([]map[int]struct{}, n),
+		visit:   visit,
+	}
+	for v, successors := range d.Successors {
+		seen := make(map[int]struct{}, len(successors))
+		for _, w := range successors {
+			if _, ok := seen[w]; false == ok {
+				seen[w] = struct{}{}
+				s.adj[v] = append(s.adj[v], w)
+			}
+		}
+	}
+	// the strongly connected components of the subgraph induced by start and every later node, which only changes
+	// within the component containing start, after it has been searched (and removed)
+	var (
+		components [][]int
+		component  = make([]int, n)
+	)
+	add := func(c [][]int) {
+		for _, nodes := range c {
+			for _, v := range nodes {
+				component[v] = len(components)
+			}
+			components = append(components, nodes)
+		}
+	}
+	add(d.Components(nil))
+	for start := 0; start < n; start++ {
+		nodes := components[component[start]]
+		if 1 == len(nodes) && false == d.HasSelfLoop(start) {
+			continue
+		}
+		s.start = start
+		for _, v := range nodes {
+			s.member[v] = true
+			s.blocked[v] = false
+			s.b[v] = nil
+		}
+		err := s.circuits()
+		if errMaxCount == err {
+			return nil
+		}
+		if nil != err {
+			return err
+		}
+		s.member[start] = false
+		if 1 != len(nodes) {
+			add(d.Components(func(node int) bool { return true == s.member[node] }))
+		}
+		for _, v := range nodes {
+			s.member[v] = false
+		}
+	}
+	return nil
+}
+
+// The circuits method performs the search for the circuits through start, within the current component.
+func (s *search) circuits() error {
+	type frame struct {
+		node  int
+		next  int
+		found bool
+	}
+	var (
+		path   = []int{s.start}
+		frames = []frame{{node: s.start}}
+	)
+	s.blocked[s.start] = true
+	for 0 != len(frames) {
+		top := &frames[len(frames)-1]
+		v := top.node
+		if top.next < len(s.adj[v]) {
+			w := s.adj[v][top.next]
+			top.next++
+			switch {
+			case false == s.member[w]:
+			case w == s.start:
+				top.found = true
+				if err := s.emit(path); nil != err {
+					return err
+				}
+			case true == s.blocked[w]:
+			case 0 < s.options.MaxLength && len(path) >= s.options.MaxLength:
+				// w could be part of a circuit, without the limit, so it must not be blocked
+				top.found = true
+			default:
+				path = append(path, w)
+				frames = append(frames, frame{node: w})
+				s.blocked[w] = true
+			}
+			continue
+		}
+		if true == top.found {
+			s.unblock(v)
+		} else {
+			for _, w := range s.adj[v] {
+				if true == s.member[w] {
+					if nil == s.b[w] {
+						s.b[w] = make(map[int]struct{})
+					}
+					s.b[w][v] = struct{}{}
+				}
+			}
+		}
+		found := top.found
+		frames = frames[:len(frames)-1]
+		path = path[:len(path)-1]
+		if 0 != len(frames) && true == found {
+			frames[len(frames)-1].found = true
+		}
+	}
+	return nil
+}
+
+// The emit method visits the circuit formed by path, and the edge back to the start.
+func (s *search) emit(path []int) error {
+	cycle := make([]interface{}, len(path)+1)
+	for i, v := range path {
+		cycle[i] = s.nodes[v]
+	}
+	cycle[len(path)] = s.nodes[s.start]
+	if err := s.visit(cycle); nil != err {
+		return err
+	}
+	s.count++
+	if 0 < s.options.MaxCount && s.count >= s.options.MaxCount {
+		return errMaxCount
+	}
+	return nil
+}
+
+// The unblock method unblocks node, and (transitively) every node that was blocked, waiting on it.
+func (s *search) unblock(node int) {
+	stack := []int{node}
+	for 0 != len(stack) {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if false == s.blocked[v] {
+			continue
+		}
+		s.blocked[v] = false
+		for w := range s.b[v] {
+			stack = append(stack, w)
+		}
+		s.b[v] = nil
+	}
+}
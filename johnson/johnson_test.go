@@ -0,0 +1,199 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package johnson
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+)
+
+// The collect function returns every circuit, formatted, in the order they were visited.
+func collect(o Options, g graph.Graph) []string {
+	var cycles []string
+	if err := o.Circuits(g, func(cycle []interface{}) error {
+		cycles = append(cycles, fmt.Sprint(cycle))
+		return nil
+	}); nil != err {
+		panic(err)
+	}
+	return cycles
+}
+
+// The complete function returns the complete directed graph, of n nodes, without self-loops.
+func complete(n int) *graph.Adjacency {
+	var g graph.Adjacency
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				g.AddEdge(i, j)
+			}
+		}
+	}
+	return &g
+}
+
+// The bruteForce function returns every elementary circuit of g, of at most maxLength, formatted like Circuits,
+// sorted, by searching every simple path, from every node, only via later nodes.
+func bruteForce(g graph.Graph, maxLength int) []string {
+	nodes := g.Nodes()
+	order := make(map[interface{}]int)
+	for i, v := range nodes {
+		order[v] = i
+	}
+	var (
+		cycles []string
+		search func(path []interface{}, onPath map[interface{}]bool)
+	)
+	search = func(path []interface{}, onPath map[interface{}]bool) {
+		seen := make(map[interface{}]bool)
+		for _, w := range g.Successors(path[len(path)-1]) {
+			if seen[w] {
+				continue
+			}
+			seen[w] = true
+			if w == path[0] {
+				cycles = append(cycles, fmt.Sprint(append(append([]interface{}(nil), path...), w)))
+				continue
+			}
+			if onPath[w] || order[w] < order[path[0]] || (0 < maxLength && len(path) >= maxLength) {
+				continue
+			}
+			onPath[w] = true
+			search(append(path, w), onPath)
+			onPath[w] = false
+		}
+	}
+	for _, v := range nodes {
+		search([]interface{}{v}, map[interface{}]bool{v: true})
+	}
+	sort.Strings(cycles)
+	return cycles
+}
+
+func TestCircuits(t *testing.T) {
+	var g graph.Adjacency
+	for _, e := range [][2]string{
+		{"a", "b"}, {"b", "c"}, {"c", "a"}, {"b", "a"}, {"c", "c"}, {"c", "d"}, {"d", "b"}, {"d", "e"}, {"e", "e"},
+		{"a", "b"},
+	} {
+		g.AddEdge(e[0], e[1])
+	}
+	if s := fmt.Sprint(collect(Options{}, &g)); s != "[[a b c a] [a b a] [b c d b] [c c] [e e]]" {
+		t.Fatal(s)
+	}
+	if s := fmt.Sprint(collect(Options{MaxLength: 2}, &g)); s != "[[a b a] [c c] [e e]]" {
+		t.Fatal(s)
+	}
+	if s := fmt.Sprint(collect(Options{MaxCount: 3}, &g)); s != "[[a b c a] [a b a] [b c d b]]" {
+		t.Fatal(s)
+	}
+	if s := fmt.Sprint(collect(Options{MaxCount: 2, MaxLength: 1}, &g)); s != "[[c c] [e e]]" {
+		t.Fatal(s)
+	}
+}
+
+func TestCircuits_acyclic(t *testing.T) {
+	var g graph.Adjacency
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 3)
+	g.AddEdge(1, 3)
+	if cycles := collect(Options{}, &g); nil != cycles {
+		t.Fatal(cycles)
+	}
+	if cycles := collect(Options{}, &graph.Adjacency{}); nil != cycles {
+		t.Fatal(cycles)
+	}
+}
+
+func TestCircuits_complete(t *testing.T) {
+	// sum, for k from 2 to n, of (n choose k) * (k-1)!
+	for n, expected := range []int{0, 0, 1, 5, 20, 84, 409, 2365} {
+		if cycles := collect(Options{}, complete(n)); len(cycles) != expected {
+			t.Error(n, len(cycles))
+		}
+	}
+}
+
+func TestCircuits_error(t *testing.T) {
+	expected := errors.New("some error")
+	var calls int
+	err := Circuits(complete(5), func(cycle []interface{}) error {
+		calls++
+		if 10 == calls {
+			return expected
+		}
+		return nil
+	})
+	if err != expected || 10 != calls {
+		t.Fatal(err, calls)
+	}
+}
+
+func TestCircuits_panic(t *testing.T) {
+	defer func() {
+		if r := fmt.Sprint(recover()); r != "[Options.Circuits] visit must be non-nil" {
+			t.Fatal(r)
+		}
+	}()
+	_ = Circuits(complete(2), nil)
+}
+
+func TestCircuits_long(t *testing.T) {
+	const n = 200000
+	var g graph.Adjacency
+	for i := 0; i < n; i++ {
+		g.AddEdge(i, (i+1)%n)
+	}
+	var cycles [][]interface{}
+	if err := Circuits(&g, func(cycle []interface{}) error {
+		cycles = append(cycles, cycle)
+		return nil
+	}); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(cycles) || n+1 != len(cycles[0]) || 0 != cycles[0][0] || n-1 != cycles[0][n-1] || 0 != cycles[0][n] {
+		t.Fatal(len(cycles))
+	}
+}
+
+func TestCircuits_random(t *testing.T) {
+	rand.Seed(44411)
+	for x := 0; x < 300; x++ {
+		var (
+			g graph.Adjacency
+			n = 1 + rand.Intn(8)
+		)
+		for i := 0; i < n; i++ {
+			g.AddNode(i)
+		}
+		for i := rand.Intn(3 * n); i > 0; i-- {
+			g.AddEdge(rand.Intn(n), rand.Intn(n))
+		}
+		for _, maxLength := range []int{0, 1, 2, 3} {
+			actual := collect(Options{MaxLength: maxLength}, &g)
+			sort.Strings(actual)
+			if expected := bruteForce(&g, maxLength); fmt.Sprint(actual) != fmt.Sprint(expected) {
+				t.Fatal(x, maxLength, actual, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package kcycles provides enumeration of every short directed cycle (e.g. triangles), up to a maximum length, in
+// large graphs, which, unlike the johnson package, is parallelisable over the starting nodes, and only explores
+// paths that could return to their start within the remaining length, meaning it's efficient for small lengths.
+//
+// Each cycle is found exactly once, from it's earliest node (in the order nodes were returned by the graph, see also
+// graph.Graph), which is therefore it's canonical rotation, meaning no memory is required to deduplicate rotations,
+// and the cycles are streamed, so memory usage is bounded by the size of the graph.
+package kcycles
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+type (
+	// Options configures Cycles, where MaxLength is required.
+	Options struct {
+		// MinLength is the minimum length (the number of edges) of cycles that will be visited, and defaults to one
+		// (an edge from a node to itself), if it's zero or negative.
+		MinLength int
+		// MaxLength is the maximum length (the number of edges) of cycles that will be visited, and must be positive.
+		MaxLength int
+		// Workers is the number of goroutines that will search for cycles, each searching from one starting node at
+		// a time, and defaults to one, in which case the search is performed in the calling goroutine, and the order
+		// cycles are visited in is deterministic.
+		Workers int
+	}
+
+	// The enumeration struct holds the state shared between the workers, for a single call to Options.Cycles.
+	enumeration struct {
+		options      Options
+		nodes        []interface{}
+		successors   [][]int
+		predecessors [][]int
+		visit        func(cycle []interface{}) error
+		next         int64
+		stopped      int32
+		mu           sync.Mutex
+		err          error
+	}
+
+	// The search struct holds the state for a single worker.
+	search struct {
+		*enumeration
+		start  int
+		path   []int
+		onPath []bool
+		// distance contains the length of the shortest path from each node to start, or -1
+		distance []int
+		touched  []int
+	}
+)
+
+// Cycles is Options.Cycles, using the default options, with the given MaxLength.
+func Cycles(g graph.Graph, maxLength int, visit func(cycle []interface{}) error) error {
+	return Options{MaxLength: maxLength}.Cycles(g, visit)
+}
+
+// Cycles calls visit with every (elementary) cycle of g, with a length between MinLength and MaxLength, inclusive,
+// stopping and returning the first error that visit returns, if any. Each cycle is formatted like
+// floyds.BranchingDetector.Cycle, starting and ending with the same node, which is it's earliest node, in the order
+// nodes were returned by g, e.g. `[a b c a]`. Duplicate edges are ignored, and each cycle is a new slice, which
+// visit may retain. Calls to visit are never concurrent, even if there are multiple Workers, and no further calls
+// will be made after it returns an error. A nil visit, or a MaxLength that isn't positive, will cause a panic.
+func (o Options) Cycles(g graph.Graph, visit func(cycle []interface{}) error) error {
+	if nil == visit {
+		panic(errors.New("[Options.Cycles] visit must be non-nil"))
+	}
+	if 0 >= o.MaxLength {
+		panic(errors.New("[Options.Cycles] max length must be positive"))
+	}
+	if 0 >= o.MinLength {
+		o.MinLength = 1
+	}
+	d := digraph.New(g)
+	e := &enumeration{
+		options:      o,
+		nodes:        d.Nodes,
+		successors:   make([][]int, len(d.Nodes)),
+		predecessors: make([][]int, len(d.Nodes)),
+		visit:        visit,
+	}
+	for v, successors := range d.Successors {
+		seen := make(map[int]struct{}, len(successors))
+		for _, w := range successors {
+			if _, ok := seen[w]; false == ok {
+				seen[w] = struct{}{}
+				e.successors[v] = append(e.successors[v], w)
+				e.predecessors[w] = append(e.predecessors[w], v)
+			}
+		}
+	}
+	if 1 >= o.Workers {
+		e.work()
+		return e.err
+	}
+	var wg sync.WaitGroup
+	wg.Add(o.Workers)
+	for i := 0; i < o.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			e.work()
+		}()
+	}
+	wg.Wait()
+	return e.err
+}
+
+// The work method searches from each starting node, that hasn't been claimed by another worker, until there are none
+// remaining, or the enumeration is stopped.
+func (e *enumeration) work() {
+	n := len(e.nodes)
+	s := search{
+		enumeration: e,
+		onPath:      make([]bool, n),
+		distance:    make([]int, n),
+	}
+	for i := range s.distance {
+		s.distance[i] = -1
+	}
+	for 0 == atomic.LoadInt32(&e.stopped) {
+		start := int(atomic.AddInt64(&e.next, 1) - 1)
+		if start >= n {
+			return
+		}
+		s.run(start)
+	}
+}
+
+// The emit method visits a cycle, unless the enumeration has been stopped, stopping it if visit returns an error.
+func (e *enumeration) emit(path []int) {
+	cycle := make([]interface{}, len(path)+1)
+	for i, v := range path {
+		cycle[i] = e.nodes[v]
+	}
+	cycle[len(path)] = cycle[0]
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if 0 != atomic.LoadInt32(&e.stopped) {
+		return
+	}
+	if err := e.visit(cycle); nil != err {
+		e.err = err
+		atomic.StoreInt32(&e.stopped, 1)
+	}
+}
+
+// The run method visits every cycle that starts with start, via only later nodes.
+func (s *search) run(start int) {
+	s.start = start
+	s.reach()
+	defer func() {
+		for _, v := range s.touched {
+			s.distance[v] = -1
+		}
+		s.touched = s.touched[:0]
+	}()
+	if -1 == s.distance[start] {
+		return
+	}
+	s.path = append(s.path[:0], start)
+	s.onPath[start] = true
+	s.extend(start)
+	s.onPath[start] = false
+}
+
+// The reach method sets the distance from every later node, to start, that is at most MaxLength - 1, using a
+// breadth first search, backwards, and sets the distance of start to zero, only if it could be part of a cycle.
+func (s *search) reach() {
+	queue := []int{s.start}
+	for depth := 1; depth < s.options.MaxLength && 0 != len(queue); depth++ {
+		var next []int
+		for _, v := range queue {
+			for _, u := range s.predecessors[v] {
+				if u > s.start && -1 == s.distance[u] {
+					s.distance[u] = depth
+					s.touched = append(s.touched, u)
+					next = append(next, u)
+				}
+			}
+		}
+		queue = next
+	}
+	// start can only be part of a cycle if one of it's successors can reach it
+	for _, w := range s.successors[s.start] {
+		if w == s.start || -1 != s.distance[w] {
+			s.distance[s.start] = 0
+			s.touched = append(s.touched, s.start)
+			return
+		}
+	}
+}
+
+// The extend method extends the path, via each successor of v, which is the last node of the path, visiting any
+// cycles that are formed, and only following successors that could return to the start, within MaxLength.
+func (s *search) extend(v int) {
+	for _, w := range s.successors[v] {
+		if 0 != atomic.LoadInt32(&s.stopped) {
+			return
+		}
+		if w == s.start {
+			if len(s.path) >= s.options.MinLength {
+				s.emit(s.path)
+			}
+			continue
+		}
+		if w < s.start || s.onPath[w] || -1 == s.distance[w] || len(s.path)+s.distance[w] > s.options.MaxLength {
+			continue
+		}
+		s.path = append(s.path, w)
+		s.onPath[w] = true
+		s.extend(w)
+		s.onPath[w] = false
+		s.path = s.path[:len(s.path)-1]
+	}
+}
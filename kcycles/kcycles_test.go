@@ -0,0 +1,175 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kcycles
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/johnson"
+)
+
+// The collect function returns every cycle, formatted, in the order they were visited.
+func collect(o Options, g graph.Graph) []string {
+	var cycles []string
+	if err := o.Cycles(g, func(cycle []interface{}) error {
+		cycles = append(cycles, fmt.Sprint(cycle))
+		return nil
+	}); nil != err {
+		panic(err)
+	}
+	return cycles
+}
+
+func TestCycles(t *testing.T) {
+	var g graph.Adjacency
+	for _, e := range []string{"ab", "bc", "ca", "ba", "cd", "dd", "de", "ea", "ab"} {
+		g.AddEdge(string(e[0]), string(e[1]))
+	}
+	for _, c := range []struct {
+		Options  Options
+		Expected string
+	}{
+		{Options{MaxLength: 1}, "[[d d]]"},
+		{Options{MaxLength: 2}, "[[a b a] [d d]]"},
+		{Options{MaxLength: 3}, "[[a b c a] [a b a] [d d]]"},
+		{Options{MaxLength: 5}, "[[a b c a] [a b c d e a] [a b a] [d d]]"},
+		{Options{MinLength: 3, MaxLength: 5}, "[[a b c a] [a b c d e a]]"},
+		{Options{MinLength: 6, MaxLength: 8}, "[]"},
+	} {
+		if actual := fmt.Sprint(collect(c.Options, &g)); actual != c.Expected {
+			t.Error(c.Options, actual)
+		}
+	}
+}
+
+func TestCycles_rotations(t *testing.T) {
+	// the same triangle, where the earliest node is not the first in it's edges
+	var g graph.Adjacency
+	g.AddNode("x")
+	g.AddEdge("b", "c")
+	g.AddEdge("c", "x")
+	g.AddEdge("x", "b")
+	if actual := fmt.Sprint(collect(Options{MaxLength: 3}, &g)); actual != "[[x b c x]]" {
+		t.Fatal(actual)
+	}
+}
+
+func TestCycles_error(t *testing.T) {
+	var g graph.Adjacency
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			g.AddEdge(i, j)
+		}
+	}
+	expected := errors.New("some error")
+	for _, workers := range []int{0, 4} {
+		var calls int
+		err := Options{MaxLength: 4, Workers: workers}.Cycles(&g, func(cycle []interface{}) error {
+			calls++
+			if 10 == calls {
+				return expected
+			}
+			return nil
+		})
+		if err != expected || 10 != calls {
+			t.Fatal(workers, err, calls)
+		}
+	}
+}
+
+func TestCycles_panic(t *testing.T) {
+	for _, c := range []struct {
+		Options Options
+		Visit   func(cycle []interface{}) error
+		Panic   string
+	}{
+		{Options{MaxLength: 3}, nil, "[Options.Cycles] visit must be non-nil"},
+		{Options{}, func(cycle []interface{}) error { return nil }, "[Options.Cycles] max length must be positive"},
+	} {
+		func() {
+			defer func() {
+				if r := fmt.Sprint(recover()); r != c.Panic {
+					t.Error(r)
+				}
+			}()
+			_ = c.Options.Cycles(&graph.Adjacency{}, c.Visit)
+		}()
+	}
+}
+
+func TestCycles_random(t *testing.T) {
+	rand.Seed(71233)
+	for x := 0; x < 300; x++ {
+		var (
+			g graph.Adjacency
+			n = 1 + rand.Intn(9)
+		)
+		for i := 0; i < n; i++ {
+			g.AddNode(i)
+		}
+		for i := rand.Intn(4 * n); i > 0; i-- {
+			g.AddEdge(rand.Intn(n), rand.Intn(n))
+		}
+		for _, maxLength := range []int{1, 2, 3, 5} {
+			var expected []string
+			if err := (johnson.Options{MaxLength: maxLength}).Circuits(&g, func(cycle []interface{}) error {
+				expected = append(expected, fmt.Sprint(cycle))
+				return nil
+			}); nil != err {
+				t.Fatal(err)
+			}
+			sort.Strings(expected)
+			for _, workers := range []int{1, 3} {
+				actual := collect(Options{MaxLength: maxLength, Workers: workers}, &g)
+				sort.Strings(actual)
+				if fmt.Sprint(actual) != fmt.Sprint(expected) {
+					t.Fatal(x, maxLength, workers, actual, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestCycles_large(t *testing.T) {
+	// a large sparse graph, where every node is part of a triangle, and a long ring, which must not be visited
+	const n = 300000
+	var g graph.Adjacency
+	for i := 0; i < n; i++ {
+		g.AddEdge(i, (i+1)%n)
+		if 0 == i%3 {
+			g.AddEdge(i+2, i)
+		}
+	}
+	var count int
+	if err := (Options{MinLength: 2, MaxLength: 6, Workers: 4}).Cycles(&g, func(cycle []interface{}) error {
+		if 4 != len(cycle) || 0 != cycle[0].(int)%3 || cycle[0] != cycle[3] {
+			t.Error(cycle)
+		}
+		count++
+		return nil
+	}); nil != err {
+		t.Fatal(err)
+	}
+	if n/3 != count {
+		t.Fatal(count)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package nivasch provides means of detecting cycles, using Nivasch's stack algorithm, for steps that are totally
+// ordered, with an API that mirrors the Detector from the floyds package, where possible.
+package nivasch
+
+import (
+	"errors"
+)
+
+/*
+The Detector struct is a cycle detector using Nivasch's stack algorithm, which requires that the steps are totally
+ordered, by a provided less function. It keeps a stack of steps, that are strictly increasing from the bottom, and
+every new step pops any larger steps from the top, until the top is not greater than it, detecting a cycle if they are
+equal, and pushing it otherwise. The smallest step in the cycle will never be popped, once it's pushed, which means the
+cycle will be detected on it's second occurrence, which is always within one period of the cycle start, before
+mu + 2 * lambda steps.
+
+The length of the cycle (lambda) is exact, and the start (mu) is bounded, since the step that was matched was the first
+occurrence of the smallest step in the cycle, which must be within one period of the start. Like gosper.Detector, next
+is never called while detecting a cycle using the `Hare` method.
+
+The memory usage is proportional to the size of the stack, which is O(log n) on average, for random sequences, but may
+be O(n) at worst, for increasing sequences. This implementation treats the current state as immutable, like
+floyds.Detector, by storing the stack as a linked list, which may be shared between detectors. If you call any
+methods on something that was not constructed using the constructor, a panic will occur.
+
+Usage:
+
+	- Create with `NewDetector`, providing start, next and less.
+	- Increment with either `Hare` OR `Next`, using `Ok` check for cycles, and passing down the new structs.
+	- If you are using `Next` method, you will want to indicate done (out of bounds), by make it return false
+		from the next method, or you may not get the results you expect.
+	- Once `Ok` returns false, call `Result` for the length of the cycle (lambda) and the bounds on it's start (mu).
+
+Nivasch's stack algorithm, for reference.
+
+https://www.gabrielnivasch.org/fun/cycle-detection
+
+	stack = []
+	i = 0
+	x = x0
+	while True:
+		while stack and stack[-1].x > x:
+			stack.pop()
+		if stack and stack[-1].x == x:
+			return i - stack[-1].i  # lambda
+		stack.append((x, i))
+		i += 1
+		x = f(x)
+*/
+type Detector struct {
+	next      func(v interface{}) (step interface{}, ok bool)
+	less      func(a, b interface{}) bool
+	stack     *node
+	hare      interface{}
+	ok        bool
+	done      bool
+	hareCount int
+	match     int
+}
+
+// The node struct models an element of the stack, which is a linked list, from the top down.
+type node struct {
+	step  interface{}
+	index int
+	below *node
+}
+
+// NewDetector constructs a new Detector struct, and must provide the start step, function to resolve the next step
+// (from the previous step each time), and a less function, which must define a strict total order over the steps,
+// and is used to test for equality as well, which two steps are if neither is less than the other.
+func NewDetector(start interface{}, next func(v interface{}) (interface{}, bool), less func(a, b interface{}) bool) Detector {
+	if nil == next {
+		panic(errors.New("[NewDetector] next must be non-nil"))
+	}
+	if nil == less {
+		panic(errors.New("[NewDetector] less must be non-nil"))
+	}
+	return Detector{next, less, &node{start, 0, nil}, start, true, false, 0, 0}
+}
+
+func (f Detector) validate() {
+	if nil == f.next || nil == f.less {
+		panic(errors.New("[Detector.validate] nil property encountered, use the constructor NewDetector"))
+	}
+}
+
+// Ok will return true only if there has been no cycle detected so far.
+func (f Detector) Ok() bool {
+	f.validate()
+	return f.ok
+}
+
+// SetNext returns a new Detector that is the same as the receiver, but with the provided next function.
+func (f Detector) SetNext(next func(v interface{}) (step interface{}, ok bool)) Detector {
+	f.validate()
+	if nil == next {
+		panic(errors.New("[Detector.SetNext] you cannot set a nil next"))
+	}
+	f.next = next
+	return f
+}
+
+// SetLess returns a new Detector that is the same as the receiver, but with the provided less function.
+func (f Detector) SetLess(less func(a, b interface{}) bool) Detector {
+	f.validate()
+	if nil == less {
+		panic(errors.New("[Detector.SetLess] you cannot set a nil less"))
+	}
+	f.less = less
+	return f
+}
+
+// Hare returns a new Detector with the hare moved forward one step (which must be provided), popping any greater
+// steps off the stack, then either detecting a cycle, if the top of the stack is equal to it, or pushing it. The name
+// is for parity with floyds.Detector, since there is only one stepper. Unlike floyds.Detector, next is never called.
+func (f Detector) Hare(step interface{}) Detector {
+	f.validate()
+	if false == f.ok || true == f.done {
+		return f
+	}
+
+	f.hare = step
+	f.hareCount++
+
+	for nil != f.stack && true == f.less(step, f.stack.step) {
+		f.stack = f.stack.below
+	}
+
+	// the top of the stack is not greater than step, so it's equal if it's also not less
+	if nil != f.stack && false == f.less(f.stack.step, step) {
+		f.ok = false
+		f.match = f.stack.index
+		return f
+	}
+
+	f.stack = &node{step, f.hareCount, f.stack}
+
+	return f
+}
+
+// Next returns a new Detector with the hare moved forward one step, using next, which is otherwise the same as
+// calling `Hare` with the step.
+func (f Detector) Next() Detector {
+	f.validate()
+	if false == f.ok || true == f.done {
+		return f
+	}
+	step, ok := f.next(f.hare)
+	if false == ok {
+		// no change, exit immediately - there was no cycle
+		f.done = true
+		return f
+	}
+	return f.Hare(step)
+}
+
+// HareCount gets the number of steps that hare has taken, since the start.
+func (f Detector) HareCount() int {
+	f.validate()
+	return f.hareCount
+}
+
+// Done will return true if any calls to next have returned a false ok value.
+func (f Detector) Done() bool {
+	f.validate()
+	return f.done
+}
+
+// Result models the length of a cycle, and the bounds on it's start, as found by Detector.Result.
+type Result struct {
+	// Lambda is the length of the cycle, also known as the period.
+	Lambda int
+	// MuLower is the lower bound (inclusive) of the index of the first step that is part of the cycle, where the
+	// start step has index 0.
+	MuLower int
+	// MuUpper is the upper bound (inclusive) of the index of the first step that is part of the cycle, which will
+	// be less than MuLower + Lambda.
+	MuUpper int
+	// Minimum is the smallest step in the cycle, the step the hare was on, when the cycle was detected.
+	Minimum interface{}
+}
+
+// Result returns the length (lambda) and bounds on the start (mu) of the cycle, without calling next, the ok return
+// value will be false if no cycle has been detected yet.
+func (f Detector) Result() (result Result, ok bool) {
+	f.validate()
+	if true == f.ok {
+		return Result{}, false
+	}
+
+	result.Lambda = f.hareCount - f.match
+	result.Minimum = f.hare
+
+	// the matched step was the first occurrence of the minimum in the cycle, so it's at most one period after mu
+	result.MuUpper = f.match
+	result.MuLower = f.match - result.Lambda + 1
+	if 0 > result.MuLower {
+		result.MuLower = 0
+	}
+
+	return result, true
+}
@@ -0,0 +1,300 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package nivasch
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func lessInt(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+// mappingNext returns a next function for a random mapping over the integers [0, n).
+func mappingNext(r *rand.Rand, n int) func(v interface{}) (interface{}, bool) {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = r.Intn(n)
+	}
+	return func(v interface{}) (interface{}, bool) {
+		return m[v.(int)], true
+	}
+}
+
+// bruteForce finds mu and lambda by recording the index of every step.
+func bruteForce(start interface{}, next func(v interface{}) (interface{}, bool)) (mu, lambda int) {
+	seen := make(map[interface{}]int)
+	step := start
+	for i := 0; ; i++ {
+		if j, ok := seen[step]; ok {
+			return j, i - j
+		}
+		seen[step] = i
+		step, _ = next(step)
+	}
+}
+
+func expectPanic(t *testing.T, message string, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if err, ok := recover().(error); false == ok || nil == err || message != err.Error() {
+			t.Fatal(err)
+		}
+	}()
+	fn()
+	t.Fatal()
+}
+
+// sameState returns true if the non-func fields of a and b are equal.
+func sameState(a, b Detector) bool {
+	return a.stack == b.stack && a.hare == b.hare && a.ok == b.ok && a.done == b.done && a.hareCount == b.hareCount &&
+		a.match == b.match
+}
+
+func TestDetector_Hare(t *testing.T) {
+	r := rand.New(rand.NewSource(1294124))
+	for x := 0; x < 2000; x++ {
+		next := mappingNext(r, 1+r.Intn(200))
+		start := 0
+		mu, lambda := bruteForce(start, next)
+		f := NewDetector(start, func(v interface{}) (interface{}, bool) {
+			t.Fatal()
+			return nil, false
+		}, lessInt)
+		step := interface{}(start)
+		for f.Ok() {
+			if f.HareCount() >= mu+2*lambda {
+				t.Fatal(mu, lambda, f.HareCount())
+			}
+			if _, ok := f.Result(); true == ok {
+				t.Fatal()
+			}
+			step, _ = next(step)
+			f = f.Hare(step)
+		}
+		result, ok := f.Result()
+		if false == ok || lambda != result.Lambda || f.hare != result.Minimum ||
+			result.MuLower > mu || result.MuUpper < mu || result.MuUpper-result.MuLower >= lambda {
+			t.Fatal(mu, lambda, ok, result)
+		}
+		// verify the minimum is actually the minimum of the cycle
+		v := result.Minimum
+		for i := 0; i < lambda; i++ {
+			if v, _ = next(v); v.(int) < result.Minimum.(int) {
+				t.Fatal(v, result.Minimum)
+			}
+		}
+	}
+}
+
+func TestDetector_Next(t *testing.T) {
+	r := rand.New(rand.NewSource(5123))
+	for x := 0; x < 2000; x++ {
+		n := 1 + r.Intn(200)
+		next := mappingNext(r, n)
+		start := r.Intn(n)
+		mu, lambda := bruteForce(start, next)
+		calls := 0
+		f := NewDetector(start, func(v interface{}) (interface{}, bool) {
+			calls++
+			return next(v)
+		}, lessInt)
+		for f.Ok() {
+			f = f.Next()
+		}
+		if calls != f.HareCount() {
+			t.Fatal(calls, f.HareCount())
+		}
+		result, ok := f.Result()
+		if false == ok || lambda != result.Lambda || result.MuLower > mu || result.MuUpper < mu {
+			t.Fatal(mu, lambda, ok, result)
+		}
+	}
+}
+
+func TestDetector_bytes(t *testing.T) {
+	list := [][]byte{[]byte("d"), []byte("c"), []byte("ab"), []byte("b"), []byte("aa"), []byte("ab")}
+	next := func(v interface{}) (interface{}, bool) {
+		for i, b := range list {
+			if bytes.Equal(b, v.([]byte)) {
+				if i == len(list)-1 {
+					return list[3], true
+				}
+				return list[i+1], true
+			}
+		}
+		t.Fatal(v)
+		return nil, false
+	}
+	f := NewDetector(list[0], next, func(a, b interface{}) bool {
+		return -1 == bytes.Compare(a.([]byte), b.([]byte))
+	})
+	for f.Ok() {
+		f = f.Next()
+	}
+	// d c ab b aa ab b aa
+	result, ok := f.Result()
+	if false == ok || 3 != result.Lambda || 7 != f.HareCount() || "aa" != string(result.Minimum.([]byte)) ||
+		2 != result.MuLower || 4 != result.MuUpper {
+		t.Fatal(ok, result, f.HareCount())
+	}
+}
+
+func TestDetector_Hare_immutable(t *testing.T) {
+	f := NewDetector(10, mappingNext(rand.New(rand.NewSource(1)), 1), lessInt)
+	f = f.Hare(20)
+	f = f.Hare(30)
+	a := f.Hare(5).Hare(6)
+	b := f.Hare(40).Hare(50)
+	if false == a.Ok() || false == b.Ok() {
+		t.Fatal()
+	}
+	// 30 was popped by 5
+	if false == a.Hare(30).Ok() || true == a.Hare(6).Ok() {
+		t.Fatal()
+	}
+	if true == b.Hare(20).Ok() || false == b.Hare(25).Ok() || true == f.Hare(30).Ok() {
+		t.Fatal()
+	}
+}
+
+func TestDetector_Next_noCycle(t *testing.T) {
+	next := func(v interface{}) (interface{}, bool) {
+		if 10 == v {
+			return nil, false
+		}
+		return v.(int) + 1, true
+	}
+	f := NewDetector(0, next, lessInt)
+	for x := 0; x < 100 && false == f.Done(); x++ {
+		f = f.Next()
+	}
+	if false == f.Ok() || false == f.Done() || 10 != f.HareCount() || 10 != f.hare {
+		t.Fatal(f)
+	}
+	if result, ok := f.Result(); true == ok || (Result{}) != result {
+		t.Fatal(result)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestDetector_notOk(t *testing.T) {
+	f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+		t.Fatal()
+		return nil, false
+	}, func(a, b interface{}) bool {
+		t.Fatal()
+		return false
+	})
+	f.ok = false
+	if f2 := f.Hare(1); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestDetector_done(t *testing.T) {
+	f := NewDetector(0, func(v interface{}) (interface{}, bool) {
+		t.Fatal()
+		return nil, false
+	}, func(a, b interface{}) bool {
+		t.Fatal()
+		return false
+	})
+	f.done = true
+	if f2 := f.Hare(1); false == sameState(f, f2) || false == f2.Done() {
+		t.Fatal(f2)
+	}
+	if f2 := f.Next(); false == sameState(f, f2) {
+		t.Fatal(f2)
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	next := func(v interface{}) (interface{}, bool) {
+		return "next", true
+	}
+	f := NewDetector(23, next, lessInt)
+	if nil == f.next || nil == f.less || nil == f.stack || 23 != f.stack.step || 0 != f.stack.index ||
+		nil != f.stack.below || 23 != f.hare || false == f.ok || true == f.done || 0 != f.hareCount || 0 != f.match {
+		t.Fatal(f)
+	}
+}
+
+func TestNewDetector_panic(t *testing.T) {
+	expectPanic(t, "[NewDetector] next must be non-nil", func() {
+		NewDetector(nil, nil, lessInt)
+	})
+	expectPanic(t, "[NewDetector] less must be non-nil", func() {
+		NewDetector(nil, mappingNext(rand.New(rand.NewSource(1)), 1), nil)
+	})
+}
+
+func TestDetector_SetNext(t *testing.T) {
+	f := NewDetector(1, mappingNext(rand.New(rand.NewSource(1)), 1), lessInt)
+	f = f.SetNext(func(a interface{}) (interface{}, bool) {
+		if 12 != a {
+			t.Fatal()
+		}
+		return "aa", true
+	})
+	if n, ok := f.next(12); false == ok || "aa" != n {
+		t.Fatal()
+	}
+	expectPanic(t, "[Detector.SetNext] you cannot set a nil next", func() {
+		f.SetNext(nil)
+	})
+}
+
+func TestDetector_SetLess(t *testing.T) {
+	f := NewDetector(1, mappingNext(rand.New(rand.NewSource(1)), 1), lessInt)
+	f = f.SetLess(func(a, b interface{}) bool {
+		if 1 != a || 2 != b {
+			t.Fatal()
+		}
+		return true
+	})
+	if true != f.less(1, 2) {
+		t.Fatal()
+	}
+	expectPanic(t, "[Detector.SetLess] you cannot set a nil less", func() {
+		f.SetLess(nil)
+	})
+}
+
+func TestDetector_validate_panic(t *testing.T) {
+	const message = "[Detector.validate] nil property encountered, use the constructor NewDetector"
+	for _, fn := range []func(f Detector){
+		func(f Detector) { f.Ok() },
+		func(f Detector) { f.Done() },
+		func(f Detector) { f.HareCount() },
+		func(f Detector) { f.Hare(nil) },
+		func(f Detector) { f.Next() },
+		func(f Detector) { f.Result() },
+		func(f Detector) { f.SetNext(nil) },
+		func(f Detector) { f.SetLess(nil) },
+	} {
+		expectPanic(t, message, func() { fn(Detector{}) })
+	}
+}
@@ -0,0 +1,399 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package pretty provides a printer for arbitrary Go values, that is safe to use with cyclic values, printing a
+// marker like `<cycle → .Parent>` in place of any reference (pointer, map, or slice) that refers back to one of it's
+// ancestors, as detected using the exact mode of floyds.BranchingDetector. The output is similar to fmt's `%+v`,
+// except that pointers are always followed, and it may be limited in depth and width.
+package pretty
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+	"github.com/joeycumines/go-detect-cycle/internal/ref"
+)
+
+type (
+	// Config models the options for printing values, the zero value printing values in their entirety.
+	Config struct {
+		// MaxDepth limits the number of levels of nested structs, maps, slices, and arrays that will be printed, with
+		// any beyond the limit printed as `<max depth>`, and is unlimited if it's zero or negative.
+		MaxDepth int
+		// MaxWidth limits the number of elements of each map, slice, or array that will be printed, with any
+		// remaining elements summarised like `...(3 more)`, and is unlimited if it's zero or negative.
+		MaxWidth int
+		// Methods enables using the Error or String methods, of any values that implement error or fmt.Stringer,
+		// which is disabled by default, since such methods may not be safe to call on cyclic values.
+		Methods bool
+	}
+
+	// The style type controls the format of the output, and maps to the fmt flags supported by Formatter.
+	style int
+
+	// The printer struct holds the state for printing a single value.
+	printer struct {
+		config Config
+		style  style
+		// maxDepth is the effective MaxDepth, which is unlimited if it's zero, or noNesting
+		maxDepth int
+		b        strings.Builder
+	}
+
+	// The formatter struct implements fmt.Formatter, for Formatter.
+	formatter struct {
+		config Config
+		value  interface{}
+	}
+)
+
+const (
+	// styleValues is like fmt's `%v`.
+	styleValues style = iota
+	// styleFields is like fmt's `%+v`, including the names of struct fields.
+	styleFields
+	// styleSyntax is like fmt's `%#v`, including type names, and quoting strings.
+	styleSyntax
+)
+
+// noNesting is the internal maxDepth for a precision of zero, which prints nothing nested, since any MaxDepth that
+// isn't positive is unlimited.
+const noNesting = -1
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	// basicTypes maps each kind of leaf value to the predeclared type of that kind, which has no methods.
+	basicTypes = [...]reflect.Type{
+		reflect.Bool:          reflect.TypeOf(false),
+		reflect.Int:           reflect.TypeOf(int(0)),
+		reflect.Int8:          reflect.TypeOf(int8(0)),
+		reflect.Int16:         reflect.TypeOf(int16(0)),
+		reflect.Int32:         reflect.TypeOf(int32(0)),
+		reflect.Int64:         reflect.TypeOf(int64(0)),
+		reflect.Uint:          reflect.TypeOf(uint(0)),
+		reflect.Uint8:         reflect.TypeOf(uint8(0)),
+		reflect.Uint16:        reflect.TypeOf(uint16(0)),
+		reflect.Uint32:        reflect.TypeOf(uint32(0)),
+		reflect.Uint64:        reflect.TypeOf(uint64(0)),
+		reflect.Uintptr:       reflect.TypeOf(uintptr(0)),
+		reflect.Float32:       reflect.TypeOf(float32(0)),
+		reflect.Float64:       reflect.TypeOf(float64(0)),
+		reflect.Complex64:     reflect.TypeOf(complex64(0)),
+		reflect.Complex128:    reflect.TypeOf(complex128(0)),
+		reflect.String:        reflect.TypeOf(""),
+		reflect.UnsafePointer: reflect.TypeOf(unsafe.Pointer(nil)),
+	}
+)
+
+// Sprint is Config.Sprint, using the default config.
+func Sprint(v interface{}) string {
+	return Config{}.Sprint(v)
+}
+
+// Fprint is Config.Fprint, using the default config.
+func Fprint(w io.Writer, v interface{}) (int, error) {
+	return Config{}.Fprint(w, v)
+}
+
+// Formatter is Config.Formatter, using the default config.
+func Formatter(v interface{}) fmt.Formatter {
+	return Config{}.Formatter(v)
+}
+
+// Sprint returns v, formatted like fmt's `%+v`, but following pointers, and printing markers in place of any cycles.
+func (c Config) Sprint(v interface{}) string {
+	return c.print(v, styleFields, c.maxDepth())
+}
+
+// Fprint writes v to w, formatted like Sprint, returning the number of bytes written, and any error.
+func (c Config) Fprint(w io.Writer, v interface{}) (int, error) {
+	return io.WriteString(w, c.Sprint(v))
+}
+
+// Formatter wraps v, in a fmt.Formatter, that may be used with any verb, e.g. `fmt.Printf("%+v", c.Formatter(v))`,
+// supporting the `+` flag (struct field names), and the `#` flag (type names, and quoted strings), any precision
+// overriding MaxDepth, e.g. `%.2v` will print at most two levels.
+func (c Config) Formatter(v interface{}) fmt.Formatter {
+	return formatter{c, v}
+}
+
+// Format implements fmt.Formatter.
+func (f formatter) Format(s fmt.State, verb rune) {
+	s2 := styleValues
+	if s.Flag('#') {
+		s2 = styleSyntax
+	} else if s.Flag('+') {
+		s2 = styleFields
+	}
+	maxDepth := f.config.maxDepth()
+	if precision, ok := s.Precision(); true == ok {
+		maxDepth = precision
+		if 0 == precision {
+			// a precision of zero means print nothing nested, rather than unlimited
+			maxDepth = noNesting
+		}
+	}
+	_, _ = io.WriteString(s, f.config.print(f.value, s2, maxDepth))
+}
+
+// The maxDepth method returns the effective MaxDepth, where zero is unlimited.
+func (c Config) maxDepth() int {
+	if 0 > c.MaxDepth {
+		return 0
+	}
+	return c.MaxDepth
+}
+
+func (c Config) print(v interface{}, s style, maxDepth int) string {
+	p := printer{config: c, style: s, maxDepth: maxDepth}
+	p.print(floyds.NewExactBranchingDetector(ref.Step{}, ref.StepKey), reflect.ValueOf(v), "", 0)
+	return p.b.String()
+}
+
+// The print method writes v, which is located at path, and is nested depth levels deep.
+func (p *printer) print(f floyds.BranchingDetector, v reflect.Value, path string, depth int) {
+	if false == v.IsValid() {
+		p.b.WriteString("<nil>")
+		return
+	}
+
+	if true == p.config.Methods && v.CanInterface() && (v.Type().Implements(errorType) || v.Type().Implements(stringerType)) {
+		if (reflect.Ptr == v.Kind() || reflect.Interface == v.Kind()) && v.IsNil() {
+			p.b.WriteString("<nil>")
+			return
+		}
+		p.printLeaf(v)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			p.printNil(v)
+			return
+		}
+		if key, ok := ref.KeyOf(v); true == ok {
+			g := f.Hare(ref.Step{Key: key, Path: path})
+			defer g.Clear()
+			if false == g.Ok() {
+				target := g.Cycle()[0].(ref.Step).Path
+				if "" == target {
+					target = "(root)"
+				}
+				p.b.WriteString("<cycle → ")
+				p.b.WriteString(target)
+				p.b.WriteString(">")
+				return
+			}
+			f = g
+		}
+		switch v.Kind() {
+		case reflect.Ptr:
+			p.b.WriteByte('&')
+			p.print(f, v.Elem(), path, depth)
+		case reflect.Map:
+			p.printMap(f, v, path, depth)
+		default:
+			p.printElems(f, v, path, depth)
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			p.printNil(v)
+			return
+		}
+		p.print(f, v.Elem(), path, depth)
+
+	case reflect.Struct:
+		p.printStruct(f, v, path, depth)
+
+	case reflect.Array:
+		p.printElems(f, v, path, depth)
+
+	default:
+		p.printLeaf(methodless(v))
+	}
+}
+
+// The enter method returns true if a nested value at depth should be printed, writing the marker if not.
+func (p *printer) enter(depth int) bool {
+	if noNesting == p.maxDepth || (0 < p.maxDepth && depth >= p.maxDepth) {
+		p.b.WriteString("<max depth>")
+		return false
+	}
+	return true
+}
+
+// The more method returns true if the element at index i, of n elements, should be printed, writing the summary if
+// it's the first one that should not.
+func (p *printer) more(i, n int) bool {
+	if 0 < p.config.MaxWidth && i >= p.config.MaxWidth {
+		if i == p.config.MaxWidth {
+			p.sep(i)
+			fmt.Fprintf(&p.b, "...(%d more)", n-i)
+		}
+		return false
+	}
+	return true
+}
+
+// The sep method writes the separator before the element at index i.
+func (p *printer) sep(i int) {
+	if 0 == i {
+		return
+	}
+	if styleSyntax == p.style {
+		p.b.WriteString(", ")
+	} else {
+		p.b.WriteByte(' ')
+	}
+}
+
+func (p *printer) printNil(v reflect.Value) {
+	if styleSyntax != p.style {
+		switch v.Kind() {
+		case reflect.Map:
+			p.b.WriteString("map[]")
+		case reflect.Slice:
+			p.b.WriteString("[]")
+		default:
+			p.b.WriteString("<nil>")
+		}
+		return
+	}
+	if reflect.Ptr == v.Kind() {
+		fmt.Fprintf(&p.b, "(%s)(nil)", v.Type())
+		return
+	}
+	fmt.Fprintf(&p.b, "%s(nil)", v.Type())
+}
+
+// The methodless function returns v, converted to an unnamed (or predeclared) type, of the same kind, if it's type
+// has any methods, so that fmt won't call them (e.g. String, or Format), unless Methods is enabled.
+func methodless(v reflect.Value) reflect.Value {
+	t := v.Type()
+	if 0 == t.NumMethod() {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Chan:
+		return v.Convert(reflect.ChanOf(t.ChanDir(), t.Elem()))
+	case reflect.Func:
+		in := make([]reflect.Type, t.NumIn())
+		for i := range in {
+			in[i] = t.In(i)
+		}
+		out := make([]reflect.Type, t.NumOut())
+		for i := range out {
+			out[i] = t.Out(i)
+		}
+		return v.Convert(reflect.FuncOf(in, out, t.IsVariadic()))
+	}
+	return v.Convert(basicTypes[v.Kind()])
+}
+
+func (p *printer) printLeaf(v reflect.Value) {
+	if v.CanInterface() {
+		if styleSyntax == p.style {
+			fmt.Fprintf(&p.b, "%#v", v.Interface())
+		} else {
+			fmt.Fprintf(&p.b, "%v", v.Interface())
+		}
+		return
+	}
+	// unexported fields, which fmt will print without calling any methods
+	if styleSyntax == p.style {
+		fmt.Fprintf(&p.b, "%#v", v)
+	} else {
+		fmt.Fprintf(&p.b, "%v", v)
+	}
+}
+
+func (p *printer) printMap(f floyds.BranchingDetector, v reflect.Value, path string, depth int) {
+	if styleSyntax == p.style {
+		p.b.WriteString(v.Type().String())
+		p.b.WriteByte('{')
+	} else {
+		p.b.WriteString("map[")
+	}
+	if p.enter(depth) {
+		keys := ref.MapKeys(v)
+		for i, k := range keys {
+			if false == p.more(i, len(keys)) {
+				break
+			}
+			p.sep(i)
+			p.print(f, k, path, depth+1)
+			p.b.WriteByte(':')
+			p.print(f, v.MapIndex(k), path+"["+ref.FormatKey(k)+"]", depth+1)
+		}
+	}
+	if styleSyntax == p.style {
+		p.b.WriteByte('}')
+	} else {
+		p.b.WriteByte(']')
+	}
+}
+
+// The printElems method prints the slice or array v.
+func (p *printer) printElems(f floyds.BranchingDetector, v reflect.Value, path string, depth int) {
+	if styleSyntax == p.style {
+		p.b.WriteString(v.Type().String())
+		p.b.WriteByte('{')
+	} else {
+		p.b.WriteByte('[')
+	}
+	if p.enter(depth) {
+		for i := 0; i < v.Len(); i++ {
+			if false == p.more(i, v.Len()) {
+				break
+			}
+			p.sep(i)
+			p.print(f, v.Index(i), path+"["+strconv.Itoa(i)+"]", depth+1)
+		}
+	}
+	if styleSyntax == p.style {
+		p.b.WriteByte('}')
+	} else {
+		p.b.WriteByte(']')
+	}
+}
+
+func (p *printer) printStruct(f floyds.BranchingDetector, v reflect.Value, path string, depth int) {
+	if styleSyntax == p.style {
+		p.b.WriteString(v.Type().String())
+	}
+	p.b.WriteByte('{')
+	if p.enter(depth) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			p.sep(i)
+			name := t.Field(i).Name
+			if styleValues != p.style {
+				p.b.WriteString(name)
+				p.b.WriteByte(':')
+			}
+			p.print(f, v.Field(i), path+"."+name, depth+1)
+		}
+	}
+	p.b.WriteByte('}')
+}
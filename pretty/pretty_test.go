@@ -0,0 +1,204 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pretty
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type (
+	testNode struct {
+		Name     string
+		Children []*testNode
+		Parent   *testNode
+	}
+
+	testStringer struct {
+		self *testStringer
+	}
+
+	testColor int
+
+	testLeaves struct {
+		C testColor
+		D time.Duration
+		F testFunc
+		c testColor
+	}
+
+	testFunc func()
+
+	testPrivate struct {
+		name string
+		next *testPrivate
+		tags map[string]int
+	}
+)
+
+func (s *testStringer) String() string { return "stringer" }
+
+func (c testColor) String() string { return "COLOR" }
+
+func (f testFunc) String() string { return "FUNC" }
+
+func newTestTree() *testNode {
+	root := &testNode{Name: "root"}
+	root.Children = []*testNode{{Name: "a", Parent: root}, {Name: "b"}}
+	return root
+}
+
+func TestSprint(t *testing.T) {
+	var nilNode *testNode
+	for _, tc := range []struct {
+		v        interface{}
+		expected string
+	}{
+		{nil, "<nil>"},
+		{1, "1"},
+		{"a b", "a b"},
+		{nilNode, "<nil>"},
+		{[]int(nil), "[]"},
+		{map[string]int(nil), "map[]"},
+		{[]interface{}{1, "a", nil, 1.5}, "[1 a <nil> 1.5]"},
+		{map[string]int{"b": 2, "a": 1}, "map[a:1 b:2]"},
+		{struct {
+			A int
+			b string
+		}{1, "x"}, "{A:1 b:x}"},
+		{[2]*int{nil, new(int)}, "[<nil> &0]"},
+		{newTestTree(), "&{Name:root Children:[&{Name:a Children:[] Parent:<cycle → (root)>} &{Name:b Children:[] Parent:<nil>}] Parent:<nil>}"},
+	} {
+		if actual := Sprint(tc.v); actual != tc.expected {
+			t.Errorf("expected %q, actual %q", tc.expected, actual)
+		}
+	}
+}
+
+func TestSprint_cycles(t *testing.T) {
+	s := []interface{}{1, nil}
+	s[1] = s
+	m := map[string]interface{}{"a": 1}
+	m["self"] = m
+	root := &testNode{Name: "root", Parent: &testNode{Name: "parent"}}
+	root.Parent.Children = []*testNode{root.Parent}
+	p := &testPrivate{name: "p", tags: map[string]int{"x": 1}}
+	p.next = p
+	for _, tc := range []struct {
+		v        interface{}
+		expected string
+	}{
+		{s, "[1 <cycle → (root)>]"},
+		{m, `map[a:1 self:<cycle → (root)>]`},
+		{[]interface{}{m}, `[map[a:1 self:<cycle → [0]>]]`},
+		{root, "&{Name:root Children:[] Parent:&{Name:parent Children:[<cycle → .Parent>] Parent:<nil>}}"},
+		{p, "&{name:p next:<cycle → (root)> tags:map[x:1]}"},
+	} {
+		if actual := Sprint(tc.v); actual != tc.expected {
+			t.Errorf("expected %q, actual %q", tc.expected, actual)
+		}
+	}
+}
+
+func TestSprint_shared(t *testing.T) {
+	shared := &testNode{Name: "shared"}
+	if s := Sprint([]*testNode{shared, shared}); s != "[&{Name:shared Children:[] Parent:<nil>} &{Name:shared Children:[] Parent:<nil>}]" {
+		t.Fatal(s)
+	}
+}
+
+func TestConfig_limits(t *testing.T) {
+	v := []interface{}{1, []interface{}{2, []interface{}{3}}, map[int]int{1: 1, 2: 2, 3: 3}, 4, 5}
+	for _, tc := range []struct {
+		config   Config
+		expected string
+	}{
+		{Config{}, "[1 [2 [3]] map[1:1 2:2 3:3] 4 5]"},
+		{Config{MaxDepth: 1}, "[1 [<max depth>] map[<max depth>] 4 5]"},
+		{Config{MaxDepth: 2}, "[1 [2 [<max depth>]] map[1:1 2:2 3:3] 4 5]"},
+		{Config{MaxDepth: -1}, "[1 [2 [3]] map[1:1 2:2 3:3] 4 5]"},
+		{Config{MaxWidth: 2}, "[1 [2 [3]] ...(3 more)]"},
+		{Config{MaxWidth: 3}, "[1 [2 [3]] map[1:1 2:2 3:3] ...(2 more)]"},
+		{Config{MaxWidth: 1, MaxDepth: 2}, "[1 ...(4 more)]"},
+	} {
+		if actual := tc.config.Sprint(v); actual != tc.expected {
+			t.Errorf("%+v: expected %q, actual %q", tc.config, tc.expected, actual)
+		}
+	}
+}
+
+func TestConfig_Methods(t *testing.T) {
+	s := &testStringer{}
+	s.self = s
+	var nilStringer *testStringer
+	v := []interface{}{s, errors.New("some error"), nilStringer}
+	if actual := Sprint(v); actual != "[&{self:<cycle → [0]>} &{s:some error} <nil>]" {
+		t.Fatal(actual)
+	}
+	if actual := (Config{Methods: true}).Sprint(v); actual != "[stringer some error <nil>]" {
+		t.Fatal(actual)
+	}
+}
+
+func TestConfig_Methods_leaves(t *testing.T) {
+	v := testLeaves{C: 2, D: time.Second, c: 3}
+	if actual := Sprint(v); actual != "{C:2 D:1000000000 F:<nil> c:3}" {
+		t.Fatal(actual)
+	}
+	if actual := fmt.Sprintf("%#v", Formatter(v)); actual != "pretty.testLeaves{C:2, D:1000000000, F:(func())(nil), c:3}" {
+		t.Fatal(actual)
+	}
+	if actual := (Config{Methods: true}).Sprint(v); actual != "{C:COLOR D:1s F:FUNC c:3}" {
+		t.Fatal(actual)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	var b bytes.Buffer
+	if n, err := Fprint(&b, newTestTree().Children[0]); nil != err || n != b.Len() ||
+		b.String() != "&{Name:a Children:[] Parent:&{Name:root Children:[<cycle → (root)> &{Name:b Children:[] Parent:<nil>}] Parent:<nil>}}" {
+		t.Fatal(n, err, b.String())
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	root := newTestTree()
+	for _, tc := range []struct {
+		format   string
+		expected string
+	}{
+		{"%v", "&{root [&{a [] <cycle → (root)>} &{b [] <nil>}] <nil>}"},
+		{"%s", "&{root [&{a [] <cycle → (root)>} &{b [] <nil>}] <nil>}"},
+		{"%+v", "&{Name:root Children:[&{Name:a Children:[] Parent:<cycle → (root)>} &{Name:b Children:[] Parent:<nil>}] Parent:<nil>}"},
+		{"%#v", `&pretty.testNode{Name:"root", Children:[]*pretty.testNode{&pretty.testNode{Name:"a", Children:[]*pretty.testNode(nil), Parent:<cycle → (root)>}, &pretty.testNode{Name:"b", Children:[]*pretty.testNode(nil), Parent:(*pretty.testNode)(nil)}}, Parent:(*pretty.testNode)(nil)}`},
+		{"%.1v", "&{root [<max depth>] <nil>}"},
+		{"%.0v", "&{<max depth>}"},
+	} {
+		if actual := fmt.Sprintf(tc.format, Formatter(root)); actual != tc.expected {
+			t.Errorf("%s: expected %q, actual %q", tc.format, tc.expected, actual)
+		}
+	}
+	if actual := fmt.Sprintf("%#v", Formatter(map[string]interface{}{"a": nil, "b": []int{1}})); actual != `map[string]interface {}{"a":interface {}(nil), "b":[]int{1}}` {
+		t.Fatal(actual)
+	}
+	if actual := fmt.Sprintf("%v", (Config{MaxWidth: 1}).Formatter([]int{1, 2})); actual != "[1 ...(1 more)]" {
+		t.Fatal(actual)
+	}
+}
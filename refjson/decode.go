@@ -0,0 +1,360 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package refjson
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/joeycumines/go-detect-cycle/internal/jsonrules"
+)
+
+type (
+	// The decodeState struct holds the state for a single call to Unmarshal.
+	decodeState struct {
+		// created maps JSON pointers to the pointers and maps decoded at that location (there may be more than one,
+		// e.g. for pointers to pointers).
+		created map[string][]reflect.Value
+	}
+
+	// The member struct is a member of a JSON object, with it's (undecoded) value.
+	member struct {
+		name string
+		raw  json.RawMessage
+	}
+)
+
+var (
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	interfaceType       = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// The checkValid function returns an error, like the one json.Unmarshal would, if data is not valid JSON.
+func checkValid(data []byte) error {
+	var raw json.RawMessage
+	return json.Unmarshal(data, &raw)
+}
+
+func (d *decodeState) register(pointer string, v reflect.Value) {
+	d.created[pointer] = append(d.created[pointer], v)
+}
+
+// The decode method decodes raw, which is located at the JSON pointer, into the settable value v.
+func (d *decodeState) decode(v reflect.Value, raw json.RawMessage, pointer string) error {
+	raw = bytes.TrimSpace(raw)
+
+	var members []member
+	if 0 != len(raw) && '{' == raw[0] {
+		var err error
+		if members, err = parseObject(raw); nil != err {
+			return err
+		}
+		if target, ok, err := refTarget(members); nil != err {
+			return err
+		} else if true == ok {
+			return d.resolve(v, target, pointer)
+		}
+	}
+
+	if isUnmarshaler(v) || (reflect.Ptr != v.Kind() && reflect.Interface != v.Kind() && reflect.Map != v.Kind() &&
+		reflect.Slice != v.Kind() && reflect.Array != v.Kind() && reflect.Struct != v.Kind()) {
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+
+	if "null" == string(raw) {
+		if reflect.Array != v.Kind() && reflect.Struct != v.Kind() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		d.register(pointer, p)
+		if err := d.decode(p.Elem(), raw, pointer); nil != err {
+			return err
+		}
+		v.Set(p)
+		return nil
+
+	case reflect.Interface:
+		if 0 != v.NumMethod() {
+			return json.Unmarshal(raw, v.Addr().Interface())
+		}
+		switch raw[0] {
+		case '{':
+			m := reflect.ValueOf(make(map[string]interface{}, len(members)))
+			v.Set(m)
+			return d.decodeMembers(m, members, pointer)
+		case '[':
+			s := reflect.New(reflect.TypeOf([]interface{}(nil))).Elem()
+			if err := d.decodeArray(s, raw, pointer); nil != err {
+				return err
+			}
+			v.Set(s)
+			return nil
+		default:
+			return json.Unmarshal(raw, v.Addr().Interface())
+		}
+
+	case reflect.Map:
+		if '{' != raw[0] {
+			return json.Unmarshal(raw, v.Addr().Interface())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(members)))
+		}
+		return d.decodeMembers(v, members, pointer)
+
+	case reflect.Slice, reflect.Array:
+		if '[' != raw[0] {
+			// e.g. []byte, which is encoded as a string, or a type error
+			return json.Unmarshal(raw, v.Addr().Interface())
+		}
+		return d.decodeArray(v, raw, pointer)
+
+	default:
+		if '{' != raw[0] {
+			return json.Unmarshal(raw, v.Addr().Interface())
+		}
+		return d.decodeStruct(v, members, pointer)
+	}
+}
+
+// The resolve method sets v to the pointer or map that was decoded at the JSON pointer target, preferring the
+// innermost, if there are multiple that may be assigned to v.
+func (d *decodeState) resolve(v reflect.Value, target string, pointer string) error {
+	created := d.created[target]
+	for i := len(created) - 1; i >= 0; i-- {
+		if created[i].Type().AssignableTo(v.Type()) {
+			v.Set(created[i])
+			return nil
+		}
+	}
+	if _, ok := d.created[target]; true == ok {
+		return fmt.Errorf("refjson: $ref %q at %q cannot be assigned to %s", "#"+target, pointer, v.Type())
+	}
+	return fmt.Errorf("refjson: $ref %q at %q does not refer to a preceding pointer or map", "#"+target, pointer)
+}
+
+// The decodeMembers method decodes the members of an object into the map v.
+func (d *decodeState) decodeMembers(v reflect.Value, members []member, pointer string) error {
+	d.register(pointer, v)
+	t := v.Type()
+	for _, m := range members {
+		k, err := mapKey(t.Key(), m.name)
+		if nil != err {
+			return err
+		}
+		e := reflect.New(t.Elem()).Elem()
+		if err := d.decode(e, m.raw, pointer+"/"+pointerEscaper.Replace(m.name)); nil != err {
+			return err
+		}
+		v.SetMapIndex(k, e)
+	}
+	return nil
+}
+
+// The decodeArray method decodes the JSON array raw into the slice or array v.
+func (d *decodeState) decodeArray(v reflect.Value, raw json.RawMessage, pointer string) error {
+	elems, err := parseArray(raw)
+	if nil != err {
+		return err
+	}
+	if reflect.Slice == v.Kind() {
+		v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+	}
+	for i := 0; i < v.Len(); i++ {
+		if i >= len(elems) {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			continue
+		}
+		if err := d.decode(v.Index(i), elems[i], pointer+"/"+strconv.Itoa(i)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// The decodeStruct method decodes the members of an object into the struct v, matching them to fields like
+// encoding/json, ignoring any unknown members.
+func (d *decodeState) decodeStruct(v reflect.Value, members []member, pointer string) error {
+	fields := jsonrules.TypeFields(v.Type())
+	for _, m := range members {
+		field, ok := matchField(fields, m.name)
+		if false == ok {
+			continue
+		}
+		fv, err := fieldByIndexAlloc(v, field.Index)
+		if nil != err {
+			return err
+		}
+		raw := m.raw
+		if true == field.Quoted && 0 != len(raw) && '"' == raw[0] {
+			var s string
+			if err := json.Unmarshal(raw, &s); nil != err {
+				return err
+			}
+			raw = json.RawMessage(s)
+		}
+		if err := d.decode(fv, raw, pointer+"/"+pointerEscaper.Replace(m.name)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// The matchField function finds the field for a member name, preferring an exact match, like encoding/json.
+func matchField(fields []jsonrules.Field, name string) (jsonrules.Field, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return jsonrules.Field{}, false
+}
+
+// The fieldByIndexAlloc function is like reflect.Value.FieldByIndex, but allocates any nil embedded pointers.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for _, i := range index {
+		if reflect.Ptr == v.Kind() {
+			if v.IsNil() {
+				if false == v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("refjson: cannot set embedded pointer to unexported struct: %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, nil
+}
+
+// The mapKey function converts an object member name to a map key of type t, like encoding/json.
+func mapKey(t reflect.Type, name string) (reflect.Value, error) {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		k := reflect.New(t)
+		if err := k.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(name)); nil != err {
+			return reflect.Value{}, err
+		}
+		return k.Elem(), nil
+	}
+	k := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		k.SetString(name)
+		return k, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(name, 10, 64)
+		if nil != err || k.OverflowInt(n) {
+			return reflect.Value{}, &json.UnmarshalTypeError{Value: "number " + name, Type: t}
+		}
+		k.SetInt(n)
+		return k, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(name, 10, 64)
+		if nil != err || k.OverflowUint(n) {
+			return reflect.Value{}, &json.UnmarshalTypeError{Value: "number " + name, Type: t}
+		}
+		k.SetUint(n)
+		return k, nil
+	}
+	return reflect.Value{}, &json.UnmarshalTypeError{Value: "object", Type: reflect.MapOf(t, interfaceType)}
+}
+
+// The isUnmarshaler function returns true if v (which must be addressable) should be decoded using it's
+// json.Unmarshaler or encoding.TextUnmarshaler implementation.
+func isUnmarshaler(v reflect.Value) bool {
+	if reflect.Ptr == v.Kind() || reflect.Interface == v.Kind() {
+		return false
+	}
+	p := reflect.PtrTo(v.Type())
+	return p.Implements(unmarshalerType) || p.Implements(textUnmarshalerType)
+}
+
+// The refTarget function returns the JSON pointer of a "$ref" object, and true, or false, if it's not one. The URI
+// fragment is percent-decoded, but the pointer is not otherwise unescaped, since it's only compared to the (escaped)
+// pointers of decoded values.
+func refTarget(members []member) (string, bool, error) {
+	if 1 != len(members) || "$ref" != members[0].name {
+		return "", false, nil
+	}
+	var target string
+	if err := json.Unmarshal(members[0].raw, &target); nil != err {
+		return "", false, fmt.Errorf("refjson: invalid $ref: %s", members[0].raw)
+	}
+	if false == strings.HasPrefix(target, "#") {
+		return "", false, fmt.Errorf("refjson: unsupported $ref %q, only URI fragments are supported", target)
+	}
+	pointer, err := url.PathUnescape(target[1:])
+	if nil != err {
+		return "", false, fmt.Errorf("refjson: invalid $ref %q: %v", target, err)
+	}
+	return pointer, true, nil
+}
+
+// The parseObject function returns the members of the JSON object raw, in order.
+func parseObject(raw json.RawMessage) ([]member, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); nil != err {
+		return nil, err
+	}
+	var members []member
+	for dec.More() {
+		t, err := dec.Token()
+		if nil != err {
+			return nil, err
+		}
+		m := member{name: t.(string)}
+		if err := dec.Decode(&m.raw); nil != err {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// The parseArray function returns the elements of the JSON array raw, in order.
+func parseArray(raw json.RawMessage) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); nil != err {
+		return nil, err
+	}
+	var elems []json.RawMessage
+	for dec.More() {
+		var e json.RawMessage
+		if err := dec.Decode(&e); nil != err {
+			return nil, err
+		}
+		elems = append(elems, e)
+	}
+	return elems, nil
+}
@@ -0,0 +1,235 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package refjson
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+	"github.com/joeycumines/go-detect-cycle/internal/jsonrules"
+	"github.com/joeycumines/go-detect-cycle/internal/ref"
+)
+
+type (
+	// The encodeState struct holds the state for a single call to Marshal.
+	encodeState struct {
+		buf bytes.Buffer
+		// seen maps the ref.Key of every pointer and map encoded so far, to the JSON pointer of it's first occurrence.
+		seen map[interface{}]string
+	}
+
+	// The objectWriter struct writes the members of a JSON object, to an encodeState.
+	objectWriter struct {
+		e     *encodeState
+		count int
+		// only is the name of the first member, which is used to check for the reserved "$ref" object.
+		only string
+	}
+)
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	// pointerEscaper escapes reference tokens, within a JSON pointer.
+	pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+func (e *encodeState) marshal(v interface{}) error {
+	return e.encode(floyds.NewExactBranchingDetector(ref.Step{}, ref.StepKey), reflect.ValueOf(v), "", false)
+}
+
+// The encode method writes the JSON encoding of v, which is located at the JSON pointer, to the buffer, using the
+// `string` option (quoting the value), if quoted is true.
+func (e *encodeState) encode(f floyds.BranchingDetector, v reflect.Value, pointer string, quoted bool) error {
+	if false == v.IsValid() {
+		e.buf.WriteString("null")
+		return nil
+	}
+	if jsonrules.IsMarshaler(v) {
+		if reflect.Ptr != v.Kind() && v.CanAddr() && false == v.Type().Implements(marshalerType) &&
+			false == v.Type().Implements(textMarshalerType) {
+			v = v.Addr()
+		}
+		return e.encodeLeaf(v, false)
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		if reflect.Slice == v.Kind() && isBytes(v.Type()) {
+			return e.encodeLeaf(v, false)
+		}
+		key, ok := ref.KeyOf(v)
+		if false == ok {
+			// an empty slice
+			e.buf.WriteString("[]")
+			return nil
+		}
+		g := f.Hare(ref.Step{Key: key, Path: pointer})
+		defer g.Clear()
+		if false == g.Ok() {
+			// a back-reference, forming a cycle
+			target := g.Cycle()[0].(ref.Step).Path
+			if reflect.Slice == v.Kind() {
+				return &CycleError{Path: pointer, Target: target, Type: key.Type}
+			}
+			e.encodeRef(target)
+			return nil
+		}
+		if target, ok := e.seen[key]; true == ok {
+			e.encodeRef(target)
+			return nil
+		}
+		switch v.Kind() {
+		case reflect.Ptr:
+			e.seen[key] = pointer
+			return e.encode(g, v.Elem(), pointer, quoted)
+		case reflect.Map:
+			e.seen[key] = pointer
+			return e.encodeMap(g, v, pointer)
+		default:
+			return e.encodeArray(g, v, pointer)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.encode(f, v.Elem(), pointer, false)
+	case reflect.Struct:
+		return e.encodeStruct(f, v, pointer)
+	case reflect.Array:
+		return e.encodeArray(f, v, pointer)
+	default:
+		return e.encodeLeaf(v, quoted)
+	}
+}
+
+// The encodeLeaf method writes v, using encoding/json.
+func (e *encodeState) encodeLeaf(v reflect.Value, quoted bool) error {
+	b, err := json.Marshal(v.Interface())
+	if nil != err {
+		return err
+	}
+	if true == quoted {
+		if b, err = json.Marshal(string(b)); nil != err {
+			return err
+		}
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+// The encodeRef method writes a "$ref" object, referencing the JSON pointer target, as a URI fragment, where each
+// reference token is percent-encoded, as required by RFC 6901 (e.g. a space becomes `%20`).
+func (e *encodeState) encodeRef(target string) {
+	tokens := strings.Split(target, "/")
+	for i, token := range tokens {
+		tokens[i] = url.PathEscape(token)
+	}
+	b, _ := json.Marshal("#" + strings.Join(tokens, "/"))
+	e.buf.WriteString(`{"$ref":`)
+	e.buf.Write(b)
+	e.buf.WriteByte('}')
+}
+
+func (e *encodeState) encodeMap(f floyds.BranchingDetector, v reflect.Value, pointer string) error {
+	entries, ok := jsonrules.MapEntries(v)
+	if false == ok {
+		return &json.UnsupportedTypeError{Type: v.Type()}
+	}
+	o := e.object()
+	for _, entry := range entries {
+		if err := o.member(f, entry.Name, entry.Value, pointer, false); nil != err {
+			return err
+		}
+	}
+	return o.close(pointer)
+}
+
+func (e *encodeState) encodeStruct(f floyds.BranchingDetector, v reflect.Value, pointer string) error {
+	o := e.object()
+	for _, field := range jsonrules.TypeFields(v.Type()) {
+		fv, ok := jsonrules.FieldByIndex(v, field.Index)
+		if false == ok || jsonrules.IsOmitted(field, fv) {
+			continue
+		}
+		if err := o.member(f, field.Name, fv, pointer, field.Quoted); nil != err {
+			return err
+		}
+	}
+	return o.close(pointer)
+}
+
+// The encodeArray method writes the elements of the slice or array v.
+func (e *encodeState) encodeArray(f floyds.BranchingDetector, v reflect.Value, pointer string) error {
+	e.buf.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		if 0 != i {
+			e.buf.WriteByte(',')
+		}
+		if err := e.encode(f, v.Index(i), pointer+"/"+strconv.Itoa(i), false); nil != err {
+			return err
+		}
+	}
+	e.buf.WriteByte(']')
+	return nil
+}
+
+func (e *encodeState) object() *objectWriter {
+	e.buf.WriteByte('{')
+	return &objectWriter{e: e}
+}
+
+func (o *objectWriter) member(f floyds.BranchingDetector, name string, v reflect.Value, pointer string, quoted bool) error {
+	if 0 != o.count {
+		o.e.buf.WriteByte(',')
+	} else {
+		o.only = name
+	}
+	o.count++
+	b, _ := json.Marshal(name)
+	o.e.buf.Write(b)
+	o.e.buf.WriteByte(':')
+	return o.e.encode(f, v, pointer+"/"+pointerEscaper.Replace(name), quoted)
+}
+
+func (o *objectWriter) close(pointer string) error {
+	if 1 == o.count && "$ref" == o.only {
+		return fmt.Errorf("refjson: reserved object with only a $ref member, at %q", pointer)
+	}
+	o.e.buf.WriteByte('}')
+	return nil
+}
+
+// The isBytes function returns true if t is a slice type that encoding/json encodes as a base64 string.
+func isBytes(t reflect.Type) bool {
+	if reflect.Uint8 != t.Elem().Kind() {
+		return false
+	}
+	p := reflect.PtrTo(t.Elem())
+	return false == p.Implements(marshalerType) && false == p.Implements(textMarshalerType)
+}
@@ -0,0 +1,152 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package refjson provides a JSON encoder and decoder pair that preserve shared and cyclic references, such as parent
+// back-pointers, allowing cyclic Go values to round-trip through JSON. It otherwise follows the same rules as
+// encoding/json, for struct tags, json.Marshaler implementations, and so on.
+//
+// Every pointer and map is encoded in full, the first time it is encountered (in document order), and every
+// subsequent occurrence, whether it's a back-reference (forming a cycle, as detected using the exact mode of
+// floyds.BranchingDetector), or just a repeated reference, is replaced by an object like `{"$ref":"#/children/0"}`,
+// where the value of "$ref" is a URI fragment containing a JSON pointer (RFC 6901) to the first occurrence. When
+// decoding, each "$ref" is resolved to the same pointer or map that was decoded at that location, which must precede
+// it, in document order.
+//
+// Slices are always encoded in full, since sharing cannot be restored for them, meaning a cycle made up of only
+// slices will result in a *CycleError, and objects that would consist of only a "$ref" member are reserved.
+package refjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+type (
+	// CycleError models a reference cycle that cannot be encoded, since it's only made up of slices.
+	CycleError struct {
+		// Path is the JSON pointer to the slice that forms the cycle.
+		Path string
+		// Target is the JSON pointer to the first occurrence of the slice.
+		Target string
+		// Type is the type of the slice.
+		Type reflect.Type
+	}
+
+	// Encoder writes values to an output stream, like json.Encoder, using Marshal.
+	Encoder struct {
+		w              io.Writer
+		prefix, indent string
+	}
+
+	// Decoder reads values from an input stream, like json.Decoder, using Unmarshal.
+	Decoder struct {
+		dec *json.Decoder
+	}
+)
+
+// Marshal returns the JSON encoding of v, like json.Marshal, but replacing repeated pointers and maps with "$ref"
+// objects, rather than failing (or encoding them repeatedly).
+func Marshal(v interface{}) ([]byte, error) {
+	e := encodeState{seen: make(map[interface{}]string)}
+	if err := e.marshal(v); nil != err {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// MarshalIndent is like Marshal, but applies json.Indent, to format the output.
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	b, err := Marshal(v)
+	if nil != err {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, prefix, indent); nil != err {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal parses the JSON-encoded data, storing the result in the value pointed to by v, like json.Unmarshal, but
+// resolving any "$ref" objects, to the pointer or map decoded at the referenced location. The reference "#" refers
+// to v itself, if it's decoded as a struct.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if reflect.Ptr != rv.Kind() || rv.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	if err := checkValid(data); nil != err {
+		return err
+	}
+	d := decodeState{created: make(map[string][]reflect.Value)}
+	d.register("", rv)
+	return d.decode(rv.Elem(), data, "")
+}
+
+// NewEncoder returns a new Encoder, that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the encoding of v to the stream, followed by a newline, like json.Encoder.Encode.
+func (e *Encoder) Encode(v interface{}) error {
+	b, err := Marshal(v)
+	if nil != err {
+		return err
+	}
+	if "" != e.prefix || "" != e.indent {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, b, e.prefix, e.indent); nil != err {
+			return err
+		}
+		b = buf.Bytes()
+	}
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
+
+// SetIndent is json.Encoder.SetIndent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix, e.indent = prefix, indent
+}
+
+// NewDecoder returns a new Decoder, that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next JSON-encoded value from it's input, and stores it in the value pointed to by v, using
+// Unmarshal. Note that "$ref" objects may only refer to locations within the same value.
+func (d *Decoder) Decode(v interface{}) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); nil != err {
+		return err
+	}
+	return Unmarshal(raw, v)
+}
+
+// More is json.Decoder.More.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Error implements the error interface.
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("refjson: cycle of slices detected: %q (%s) refers back to %q", e.Path, e.Type, e.Target)
+}
@@ -0,0 +1,340 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package refjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type (
+	testEmbedded struct {
+		*testInner
+	}
+
+	testInner struct {
+		C int
+	}
+
+	testNode struct {
+		Name     string      `json:"name"`
+		Children []*testNode `json:"children,omitempty"`
+		Parent   *testNode   `json:"parent,omitempty"`
+	}
+)
+
+func newTestTree() *testNode {
+	root := &testNode{Name: "root"}
+	a := &testNode{Name: "a", Parent: root}
+	b := &testNode{Name: "b", Parent: root}
+	a.Children = []*testNode{{Name: "c", Parent: a}}
+	root.Children = []*testNode{a, b, a.Children[0]}
+	return root
+}
+
+func checkTestTree(t *testing.T, root *testNode) {
+	t.Helper()
+	if nil == root || "root" != root.Name || nil != root.Parent || 3 != len(root.Children) {
+		t.Fatal(root)
+	}
+	a, b, c := root.Children[0], root.Children[1], root.Children[2]
+	if "a" != a.Name || root != a.Parent || 1 != len(a.Children) || c != a.Children[0] ||
+		"b" != b.Name || root != b.Parent || 0 != len(b.Children) ||
+		"c" != c.Name || a != c.Parent || 0 != len(c.Children) {
+		t.Fatal(a, b, c)
+	}
+}
+
+func TestMarshal_tree(t *testing.T) {
+	b, err := Marshal(newTestTree())
+	if nil != err {
+		t.Fatal(err)
+	}
+	if s := string(b); s != `{"name":"root","children":[{"name":"a","children":[{"name":"c","parent":{"$ref":"#/children/0"}}],"parent":{"$ref":"#"}},{"name":"b","parent":{"$ref":"#"}},{"$ref":"#/children/0/children/0"}]}` {
+		t.Fatal(s)
+	}
+	var root *testNode
+	if err := Unmarshal(b, &root); nil != err {
+		t.Fatal(err)
+	}
+	checkTestTree(t, root)
+	// the root may also be decoded into directly, "#" refers to the pointer provided
+	root = new(testNode)
+	if err := Unmarshal(b, root); nil != err {
+		t.Fatal(err)
+	}
+	checkTestTree(t, root)
+}
+
+func TestMarshal_sameAsJSON(t *testing.T) {
+	type quoted struct {
+		A int     `json:",string"`
+		B *bool   `json:"b,string"`
+		C []byte  `json:"c"`
+		D float64 `json:"-"`
+		E string  `json:",omitempty"`
+	}
+	v := true
+	for _, v := range []interface{}{
+		nil,
+		1,
+		"a<b>",
+		[]interface{}{},
+		[]int(nil),
+		map[string]int{},
+		map[int][]string{2: {"a"}, 10: nil},
+		[2]bool{true},
+		quoted{A: 1, B: &v, C: []byte("abc"), D: 1},
+		&json.RawMessage{'{', '}'},
+		[]interface{}{json.Number("1.50"), new(int)},
+	} {
+		expected, err := json.Marshal(v)
+		if nil != err {
+			t.Fatal(err)
+		}
+		actual, err := Marshal(v)
+		if nil != err || string(expected) != string(actual) {
+			t.Fatal(string(actual), err)
+		}
+	}
+}
+
+func TestMarshal_maps(t *testing.T) {
+	m := map[string]interface{}{"a/b": nil, "c~d": nil}
+	m["a/b"] = []interface{}{m}
+	m["c~d"] = map[string]interface{}{"e": m["a/b"], "f": m}
+	b, err := MarshalIndent(m, "", "")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if s := strings.Replace(string(b), "\n", "", -1); s != `{"a/b": [{"$ref": "#"}],"c~d": {"e": [{"$ref": "#"}],"f": {"$ref": "#"}}}` {
+		t.Fatal(s)
+	}
+	n := map[string]interface{}{"x": map[string]interface{}{}}
+	n["y"] = map[string]interface{}{"a/b": n["x"], "c~d": n["x"]}
+	n["z"] = n["y"]
+	b, err = Marshal(n)
+	if nil != err || string(b) != `{"x":{},"y":{"a/b":{"$ref":"#/x"},"c~d":{"$ref":"#/x"}},"z":{"$ref":"#/y"}}` {
+		t.Fatal(string(b), err)
+	}
+	var decoded map[string]map[string]interface{}
+	if err := Unmarshal(b, &decoded); nil != err {
+		t.Fatal(err)
+	}
+	decoded["x"]["marker"] = 1
+	if 1 != decoded["y"]["a/b"].(map[string]interface{})["marker"] || 1 != decoded["y"]["c~d"].(map[string]interface{})["marker"] {
+		t.Fatal(decoded)
+	}
+	decoded["y"]["marker"] = 2
+	if 2 != decoded["z"]["marker"] {
+		t.Fatal(decoded)
+	}
+	// escaped pointers are resolved
+	if err := Unmarshal([]byte(`{"a/b":{},"c~d":{"$ref":"#/a~1b"}}`), &decoded); nil != err {
+		t.Fatal(err)
+	}
+	decoded["a/b"]["marker"] = 3
+	if 3 != decoded["c~d"]["marker"] {
+		t.Fatal(decoded)
+	}
+}
+
+func TestMarshal_percentEncoding(t *testing.T) {
+	x := map[string]interface{}{}
+	m := map[string]interface{}{"a b%": x, "c/é": x, "d": []interface{}{x}}
+	b, err := Marshal(m)
+	if nil != err || string(b) != `{"a b%":{},"c/é":{"$ref":"#/a%20b%25"},"d":[{"$ref":"#/a%20b%25"}]}` {
+		t.Fatal(string(b), err)
+	}
+	var decoded map[string]interface{}
+	if err := Unmarshal(b, &decoded); nil != err {
+		t.Fatal(err)
+	}
+	decoded["a b%"].(map[string]interface{})["marker"] = 1
+	if 1 != decoded["c/é"].(map[string]interface{})["marker"] || 1 != decoded["d"].([]interface{})[0].(map[string]interface{})["marker"] {
+		t.Fatal(decoded)
+	}
+	// externally encoded refs, with percent-encoded characters that need not be, and an escaped "/"
+	if err := Unmarshal([]byte(`{"a b":{},"c/é":{},"x":{"$ref":"#/a%20b"},"y":{"$ref":"#/c~1%C3%A9"},"z":{"$ref":"#/%61%20b"}}`), &decoded); nil != err {
+		t.Fatal(err)
+	}
+	decoded["a b"].(map[string]interface{})["marker"] = 2
+	decoded["c/é"].(map[string]interface{})["marker"] = 3
+	if 2 != decoded["x"].(map[string]interface{})["marker"] || 3 != decoded["y"].(map[string]interface{})["marker"] || 2 != decoded["z"].(map[string]interface{})["marker"] {
+		t.Fatal(decoded)
+	}
+}
+
+func TestUnmarshal_interface(t *testing.T) {
+	var v interface{}
+	if err := Unmarshal([]byte(`{"a":[1,"b",null,{"c":{"$ref":"#"}}]}`), &v); nil != err {
+		t.Fatal(err)
+	}
+	m := v.(map[string]interface{})
+	a := m["a"].([]interface{})
+	if 1.0 != a[0] || "b" != a[1] || nil != a[2] {
+		t.Fatal(a)
+	}
+	if c := a[3].(map[string]interface{})["c"].(map[string]interface{}); len(c) != 1 {
+		t.Fatal(c)
+	} else {
+		c["marker"] = true
+		if true != m["marker"] {
+			t.Fatal(m)
+		}
+	}
+}
+
+func TestMarshal_sliceCycle(t *testing.T) {
+	s := []interface{}{1, nil}
+	s[1] = s
+	_, err := Marshal(s)
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) || "/1" != cycleErr.Path || "" != cycleErr.Target ||
+		err.Error() != `refjson: cycle of slices detected: "/1" ([]interface {}) refers back to ""` {
+		t.Fatal(err)
+	}
+	// via a pointer is fine
+	p := &struct{ S []interface{} }{}
+	p.S = []interface{}{p}
+	if b, err := Marshal(p); nil != err || `{"S":[{"$ref":"#"}]}` != string(b) {
+		t.Fatal(string(b), err)
+	}
+}
+
+func TestMarshal_reserved(t *testing.T) {
+	if _, err := Marshal(map[string]int{"$ref": 1}); nil == err || err.Error() != `refjson: reserved object with only a $ref member, at ""` {
+		t.Fatal(err)
+	}
+	if b, err := Marshal(map[string]int{"$ref": 1, "a": 2}); nil != err || `{"$ref":1,"a":2}` != string(b) {
+		t.Fatal(string(b), err)
+	}
+}
+
+func TestMarshal_errors(t *testing.T) {
+	if _, err := Marshal(map[float64]int{1: 1}); nil == err {
+		t.Fatal()
+	}
+	if _, err := Marshal(func() {}); nil == err {
+		t.Fatal()
+	}
+	var unsupported *json.UnsupportedTypeError
+	if _, err := Marshal(map[string]interface{}{"a": make(chan int)}); false == errors.As(err, &unsupported) {
+		t.Fatal(err)
+	}
+	if _, err := MarshalIndent(func() {}, "", ""); nil == err {
+		t.Fatal()
+	}
+}
+
+func TestUnmarshal_errors(t *testing.T) {
+	var root *testNode
+	for _, tc := range []struct {
+		data     string
+		v        interface{}
+		expected string
+	}{
+		{`{}`, nil, "json: Unmarshal(nil)"},
+		{`{}`, root, "json: Unmarshal(nil *refjson.testNode)"},
+		{`{`, &root, "unexpected end of JSON input"},
+		{`{"parent":{"$ref":"#/children/0"}}`, &root, `refjson: $ref "#/children/0" at "/parent" does not refer to a preceding pointer or map`},
+		{`{"children":[{"$ref":"#/x"}],"x":1}`, &root, `refjson: $ref "#/x" at "/children/0" does not refer to a preceding pointer or map`},
+		{`{"A":{},"B":{"$ref":"#/A"}}`, &struct {
+			A, B *testNode
+			C    *int
+		}{}, ""},
+		{`{"A":{},"C":{"$ref":"#/A"}}`, &struct {
+			A, B *testNode
+			C    *int
+		}{}, `refjson: $ref "#/A" at "/C" cannot be assigned to *int`},
+		{`{"C":1}`, &testEmbedded{}, "refjson: cannot set embedded pointer to unexported struct: refjson.testInner"},
+		{`{"parent":{"$ref":1}}`, &root, `refjson: invalid $ref: 1`},
+		{`{"parent":{"$ref":"#/%zz"}}`, &root, `refjson: invalid $ref "#/%zz": invalid URL escape "%zz"`},
+		{`{"parent":{"$ref":"x.json#/a"}}`, &root, `refjson: unsupported $ref "x.json#/a", only URI fragments are supported`},
+		{`{"name":1}`, &root, "json: cannot unmarshal number into Go value of type string"},
+		{`{"1.5":1}`, &map[int]int{}, "json: cannot unmarshal number 1.5 into Go value of type int"},
+		{`{"1":1}`, &map[float64]int{}, "json: cannot unmarshal object into Go value of type map[float64]interface {}"},
+	} {
+		if err := Unmarshal([]byte(tc.data), tc.v); ("" == tc.expected && nil != err) || ("" != tc.expected && (nil == err || err.Error() != tc.expected)) {
+			t.Errorf("%s: %v", tc.data, err)
+		}
+	}
+}
+
+func TestUnmarshal_sameAsJSON(t *testing.T) {
+	type (
+		Inner struct {
+			C int
+		}
+		outer struct {
+			*Inner
+			A  int     `json:",string"`
+			B  []byte  `json:"b"`
+			D  [2]int  `json:"d"`
+			E  string  `json:"-"`
+			F  *string `json:"f"`
+			G  json.RawMessage
+			HI map[string]bool
+		}
+	)
+	data := `{"C":3,"A":"1","b":"YWJj","d":[1,2,3],"E":"x","f":"y","g":{"a": 1},"hi":{"t":true},"unknown":[]}`
+	var expected, actual outer
+	if err := json.Unmarshal([]byte(data), &expected); nil != err {
+		t.Fatal(err)
+	}
+	if err := Unmarshal([]byte(data), &actual); nil != err {
+		t.Fatal(err)
+	}
+	eb, _ := json.Marshal(expected)
+	ab, _ := json.Marshal(actual)
+	if string(eb) != string(ab) || 3 != actual.C || "y" != *actual.F {
+		t.Fatal(string(ab))
+	}
+}
+
+func TestEncoder_Decoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(newTestTree()); nil != err {
+		t.Fatal(err)
+	}
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(newTestTree()); nil != err {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(func() {}); nil == err {
+		t.Fatal()
+	}
+	dec := NewDecoder(&buf)
+	for x := 0; x < 2; x++ {
+		if false == dec.More() {
+			t.Fatal(x)
+		}
+		var root *testNode
+		if err := dec.Decode(&root); nil != err {
+			t.Fatal(err)
+		}
+		checkTestTree(t, root)
+	}
+	if err := dec.Decode(new(interface{})); io.EOF != err {
+		t.Fatal(err)
+	}
+}
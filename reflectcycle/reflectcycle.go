@@ -0,0 +1,165 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package reflectcycle provides means of detecting reference cycles within arbitrary Go values, using reflect, and
+// the exact mode of floyds.BranchingDetector, reporting the path to the reference that forms the cycle.
+package reflectcycle
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+	"github.com/joeycumines/go-detect-cycle/internal/ref"
+)
+
+type (
+	// Options configures the walk performed by Detect, the zero value being the default.
+	Options struct {
+		// Unexported enables walking unexported struct fields, which are otherwise skipped, like encoding/json.
+		Unexported bool
+	}
+
+	// CycleError models a reference cycle, as found by Detect.
+	CycleError struct {
+		// Path is the path to the reference that forms the cycle, from the root value, like
+		// `.Spec.Children[3].Parent`, using the same syntax as Go (pointers are dereferenced implicitly).
+		Path string
+		// Target is the path to the first occurrence of the reference, which is always a prefix of Path, and will be
+		// empty if it was the root value.
+		Target string
+		// Type is the type of the reference.
+		Type reflect.Type
+	}
+
+	// The walker struct holds the state for a single call to Options.Detect.
+	walker struct {
+		options Options
+		// done contains every reference that has been walked in it's entirety, without finding a cycle, meaning
+		// there is no need to walk it again.
+		done map[ref.Key]struct{}
+	}
+)
+
+// Detect is Options.Detect, using the default options.
+func Detect(v interface{}) error {
+	return Options{}.Detect(v)
+}
+
+// Detect walks v, returning nil if it contains no reference cycles, or a *CycleError describing the first reference
+// cycle found. Pointers, maps (values only, not keys), slices, arrays, interfaces, and struct fields (unexported ones
+// only if enabled) are walked, in order, with map keys sorted by their formatted representation, so the result is
+// stable. Each reference is walked at most once, in it's entirety, meaning values with lots of shared references
+// remain cheap to walk. Note that channels, funcs, and unsafe pointers are never walked.
+func (o Options) Detect(v interface{}) error {
+	w := walker{
+		options: o,
+		done:    make(map[ref.Key]struct{}),
+	}
+	return w.walk(
+		floyds.NewExactBranchingDetector(ref.Step{}, ref.StepKey),
+		reflect.ValueOf(v),
+		"",
+	)
+}
+
+// Error implements the error interface.
+func (e *CycleError) Error() string {
+	target := e.Target
+	if "" == target {
+		target = "(root)"
+	}
+	return fmt.Sprintf("cycle detected: %s (%s) refers back to %s", e.Path, e.Type, target)
+}
+
+func (w *walker) walk(f floyds.BranchingDetector, v reflect.Value, path string) error {
+	if false == v.IsValid() || ref.Pointerless(v.Type()) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		key, ok := ref.KeyOf(v)
+		if false == ok {
+			return nil
+		}
+		if _, ok := w.done[key]; true == ok {
+			return nil
+		}
+		g := f.Hare(ref.Step{Key: key, Path: path})
+		defer g.Clear()
+		if false == g.Ok() {
+			cycle := g.Cycle()
+			return &CycleError{
+				Path:   path,
+				Target: cycle[0].(ref.Step).Path,
+				Type:   key.Type,
+			}
+		}
+		if err := w.walkRef(g, v, path); nil != err {
+			return err
+		}
+		w.done[key] = struct{}{}
+	case reflect.Interface:
+		if false == v.IsNil() {
+			return w.walk(f, v.Elem(), path)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if field := t.Field(i); "" == field.PkgPath || true == w.options.Unexported {
+				if err := w.walk(f, v.Field(i), path+"."+field.Name); nil != err {
+					return err
+				}
+			}
+		}
+	case reflect.Array:
+		return w.walkElems(f, v, path)
+	}
+	return nil
+}
+
+// The walkRef method walks whatever the reference v refers to, after it has been passed to f.
+func (w *walker) walkRef(f floyds.BranchingDetector, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		return w.walk(f, v.Elem(), path)
+	case reflect.Map:
+		if ref.Pointerless(v.Type().Elem()) {
+			return nil
+		}
+		for _, k := range ref.MapKeys(v) {
+			if err := w.walk(f, v.MapIndex(k), path+"["+ref.FormatKey(k)+"]"); nil != err {
+				return err
+			}
+		}
+		return nil
+	default:
+		return w.walkElems(f, v, path)
+	}
+}
+
+// The walkElems method walks the elements of the slice or array v.
+func (w *walker) walkElems(f floyds.BranchingDetector, v reflect.Value, path string) error {
+	if ref.Pointerless(v.Type().Elem()) {
+		return nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := w.walk(f, v.Index(i), fmt.Sprintf("%s[%d]", path, i)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package reflectcycle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type (
+	testNode struct {
+		Name     string
+		Children []*testNode
+		Parent   *testNode
+	}
+
+	testResource struct {
+		Spec testSpec
+	}
+
+	testSpec struct {
+		Labels   map[string]interface{}
+		Children []*testNode
+		parent   *testResource
+	}
+)
+
+func TestDetect_noCycle(t *testing.T) {
+	shared := &testNode{Name: "shared"}
+	for i, v := range []interface{}{
+		nil,
+		1,
+		"a",
+		(*testNode)(nil),
+		&testNode{},
+		[]interface{}{1, "a", nil},
+		map[string]interface{}{"a": map[string]interface{}{}},
+		&testResource{Spec: testSpec{Children: []*testNode{shared, shared, {Children: []*testNode{shared}}}}},
+		[2]*testNode{shared, shared},
+		struct{ A, B interface{} }{shared, []*testNode{shared}},
+		make(chan int),
+		func() {},
+	} {
+		if err := Detect(v); nil != err {
+			t.Errorf("%d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestDetect_cycle(t *testing.T) {
+	child := &testNode{Name: "child"}
+	resource := &testResource{Spec: testSpec{Children: []*testNode{{}, {}, {}, child}}}
+	child.Parent = &testNode{Name: "parent", Children: resource.Spec.Children}
+
+	err := Detect(resource)
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) {
+		t.Fatal(err)
+	}
+	if cycleErr.Path != ".Spec.Children[3].Parent.Children" || cycleErr.Target != ".Spec.Children" ||
+		cycleErr.Type != reflect.TypeOf([]*testNode(nil)) {
+		t.Fatal(cycleErr)
+	}
+	if err.Error() != "cycle detected: .Spec.Children[3].Parent.Children ([]*reflectcycle.testNode) refers back to .Spec.Children" {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect_cycleParent(t *testing.T) {
+	root := &testNode{Name: "root"}
+	root.Children = []*testNode{{Name: "a"}, {Name: "b", Parent: root}}
+	err := Detect(root)
+	if nil == err || err.Error() != "cycle detected: .Children[1].Parent (*reflectcycle.testNode) refers back to (root)" {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect_self(t *testing.T) {
+	s := make([]interface{}, 1)
+	s[0] = s
+	m := map[string]interface{}{}
+	m["self"] = m
+	p := new(interface{})
+	*p = p
+	for _, tc := range []struct {
+		v    interface{}
+		path string
+	}{
+		{s, "[0]"},
+		{m, `["self"]`},
+		{p, ""},
+		{[]interface{}{1, map[int]interface{}{2: m}}, `[1][2]["self"]`},
+	} {
+		var cycleErr *CycleError
+		if err := Detect(tc.v); false == errors.As(err, &cycleErr) || cycleErr.Path != tc.path {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDetect_unexported(t *testing.T) {
+	resource := &testResource{}
+	resource.Spec.parent = resource
+	if err := Detect(resource); nil != err {
+		t.Fatal(err)
+	}
+	err := Options{Unexported: true}.Detect(resource)
+	if nil == err || err.Error() != "cycle detected: .Spec.parent (*reflectcycle.testResource) refers back to (root)" {
+		t.Fatal(err)
+	}
+	// unexported map keys and values must be walkable too
+	type private struct {
+		m map[interface{}]interface{}
+	}
+	v := &private{m: map[interface{}]interface{}{}}
+	v.m["a"] = v
+	if err := Detect(v); nil != err {
+		t.Fatal(err)
+	}
+	if err := (Options{Unexported: true}).Detect(v); nil == err || err.Error() != `cycle detected: .m["a"] (*reflectcycle.private) refers back to (root)` {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect_json(t *testing.T) {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(`{"a":{"b":[1,{"c":{}}]},"d":[[],[]]}`), &decoded); nil != err {
+		t.Fatal(err)
+	}
+	if err := Detect(decoded); nil != err {
+		t.Fatal(err)
+	}
+	decoded.(map[string]interface{})["a"].(map[string]interface{})["b"].([]interface{})[1].(map[string]interface{})["c"].(map[string]interface{})["e"] = decoded.(map[string]interface{})["a"]
+	if err := Detect(decoded); nil == err || err.Error() != `cycle detected: ["a"]["b"][1]["c"]["e"] (map[string]interface {}) refers back to ["a"]` {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect_sharedIsCheap(t *testing.T) {
+	// each level refers to the next level twice, which would be 2^64 paths, if references were walked more than once
+	type level struct {
+		A, B *level
+	}
+	var root *level
+	for x := 0; x < 64; x++ {
+		root = &level{root, root}
+	}
+	if err := Detect(root); nil != err {
+		t.Fatal(err)
+	}
+	root.A.B.A.B = root.A
+	if err := Detect(root); nil == err || err.Error() != "cycle detected: .A.A.A.B (*reflectcycle.level) refers back to .A" {
+		t.Fatal(err)
+	}
+}
+
+func TestCycleError_Error(t *testing.T) {
+	if s := fmt.Sprint(&CycleError{Path: ".A", Type: reflect.TypeOf(new(int))}); s != "cycle detected: .A (*int) refers back to (root)" {
+		t.Fatal(s)
+	}
+}
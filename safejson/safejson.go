@@ -0,0 +1,111 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package safejson provides drop-in replacements for the marshalling functions and encoder of encoding/json, which
+// check for reference cycles prior to encoding, using the exact mode of floyds.BranchingDetector, returning an error
+// describing the JSON path of the cycle, rather than failing deep within the encoder, or overflowing the stack.
+//
+// The check applies the same rules as encoding/json, meaning only values that would actually be encoded are walked,
+// honouring json struct tags (including "-", omitempty, and omitzero), embedded structs, and map key ordering, while
+// treating any json.Marshaler or encoding.TextMarshaler implementations as leaves (they can't be walked).
+package safejson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+type (
+	// CycleError models a reference cycle, as found by Check, and is returned by every function in this package, if
+	// the value to encode contains a cycle. It wraps a *json.UnsupportedValueError, like encoding/json would return, if
+	// it was able to detect the cycle itself.
+	CycleError struct {
+		// Path is the JSON path (like `$.spec.children[3].parent`) to the reference that forms the cycle.
+		Path string
+		// Target is the JSON path to the first occurrence of the reference, which is always a prefix of Path.
+		Target string
+		// Type is the type of the reference.
+		Type reflect.Type
+
+		value reflect.Value
+	}
+
+	// Encoder is a drop-in replacement for json.Encoder, which checks each value for cycles, before encoding it.
+	Encoder struct {
+		enc *json.Encoder
+	}
+)
+
+// Check returns a *CycleError if v contains a reference cycle, that would be encountered by encoding/json, or nil.
+func Check(v interface{}) error {
+	w := walker{done: make(map[interface{}]struct{})}
+	return w.check(reflect.ValueOf(v))
+}
+
+// Marshal is a drop-in replacement for json.Marshal, which calls Check, before encoding v.
+func Marshal(v interface{}) ([]byte, error) {
+	if err := Check(v); nil != err {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// MarshalIndent is a drop-in replacement for json.MarshalIndent, which calls Check, before encoding v.
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	if err := Check(v); nil != err {
+		return nil, err
+	}
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+// NewEncoder returns a new Encoder, that writes to w, like json.NewEncoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{enc: json.NewEncoder(w)}
+}
+
+// Encode calls Check, then writes the JSON encoding of v to the stream, like json.Encoder.Encode, meaning nothing
+// will be written if v contains a cycle.
+func (e *Encoder) Encode(v interface{}) error {
+	if err := Check(v); nil != err {
+		return err
+	}
+	return e.enc.Encode(v)
+}
+
+// SetIndent is json.Encoder.SetIndent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.enc.SetIndent(prefix, indent)
+}
+
+// SetEscapeHTML is json.Encoder.SetEscapeHTML.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.enc.SetEscapeHTML(on)
+}
+
+// Error implements the error interface.
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("safejson: cycle detected: %s (%s) refers back to %s", e.Path, e.Type, e.Target)
+}
+
+// Unwrap returns a *json.UnsupportedValueError, like the one encoding/json returns, if it detects the cycle itself.
+func (e *CycleError) Unwrap() error {
+	return &json.UnsupportedValueError{
+		Value: e.value,
+		Str:   fmt.Sprintf("encountered a cycle via %s", e.Type),
+	}
+}
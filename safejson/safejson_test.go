@@ -0,0 +1,117 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package safejson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testNode struct {
+	Name     string      `json:"name"`
+	Children []*testNode `json:"children,omitempty"`
+	Parent   *testNode   `json:"parent,omitempty"`
+}
+
+func newTestCycle() *testNode {
+	root := &testNode{Name: "root"}
+	root.Children = []*testNode{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d", Parent: root}}
+	return root
+}
+
+func TestMarshal(t *testing.T) {
+	shared := &testNode{Name: "shared"}
+	for _, v := range []interface{}{
+		nil,
+		1,
+		"a",
+		[]interface{}{1, "b", nil, map[string]interface{}{"c": []int{1}}},
+		&testNode{Name: "a", Children: []*testNode{shared, shared}},
+		map[int]*testNode{1: shared, 2: shared},
+	} {
+		expected, err := json.Marshal(v)
+		if nil != err {
+			t.Fatal(err)
+		}
+		actual, err := Marshal(v)
+		if nil != err || false == bytes.Equal(expected, actual) {
+			t.Fatal(string(actual), err)
+		}
+		expected, err = json.MarshalIndent(v, ">", "  ")
+		if nil != err {
+			t.Fatal(err)
+		}
+		actual, err = MarshalIndent(v, ">", "  ")
+		if nil != err || false == bytes.Equal(expected, actual) {
+			t.Fatal(string(actual), err)
+		}
+	}
+}
+
+func TestMarshal_cycle(t *testing.T) {
+	root := newTestCycle()
+	b, err := Marshal(root)
+	if nil != b || nil == err || err.Error() != "safejson: cycle detected: $.children[3].parent (*safejson.testNode) refers back to $" {
+		t.Fatal(string(b), err)
+	}
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) || "$.children[3].parent" != cycleErr.Path || "$" != cycleErr.Target ||
+		reflect.TypeOf(&testNode{}) != cycleErr.Type {
+		t.Fatal(err)
+	}
+	var unsupportedErr *json.UnsupportedValueError
+	if false == errors.As(err, &unsupportedErr) || "encountered a cycle via *safejson.testNode" != unsupportedErr.Str ||
+		root != unsupportedErr.Value.Interface() {
+		t.Fatal(err)
+	}
+	if b, err := MarshalIndent(newTestCycle(), "", "  "); nil != b || false == errors.As(err, &cycleErr) {
+		t.Fatal(string(b), err)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetIndent("", " ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(map[string]string{"a": "<b>"}); nil != err {
+		t.Fatal(err)
+	}
+	var cycleErr *CycleError
+	if err := enc.Encode(newTestCycle()); false == errors.As(err, &cycleErr) {
+		t.Fatal(err)
+	}
+	if err := enc.Encode([]int{1}); nil != err {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != "{\n \"a\": \"<b>\"\n}\n[\n 1\n]\n" {
+		t.Fatal(s)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(&testNode{}); nil != err {
+		t.Fatal(err)
+	}
+	// the path is the same as the one reported by Marshal
+	if err := Check(newTestCycle()); nil == err || err.Error() != "safejson: cycle detected: $.children[3].parent (*safejson.testNode) refers back to $" {
+		t.Fatal(err)
+	}
+}
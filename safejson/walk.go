@@ -0,0 +1,144 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package safejson
+
+import (
+	"reflect"
+	"strconv"
+	"unicode"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+	"github.com/joeycumines/go-detect-cycle/internal/jsonrules"
+	"github.com/joeycumines/go-detect-cycle/internal/ref"
+)
+
+// The walker struct holds the state for a single call to Check.
+type walker struct {
+	// done contains the ref.Key of every reference that has been walked in it's entirety, without finding a cycle.
+	done map[interface{}]struct{}
+}
+
+func (w *walker) check(v reflect.Value) error {
+	return w.walk(floyds.NewExactBranchingDetector(ref.Step{}, ref.StepKey), v, "$")
+}
+
+func (w *walker) walk(f floyds.BranchingDetector, v reflect.Value, path string) error {
+	if false == v.IsValid() || ref.Pointerless(v.Type()) || jsonrules.IsMarshaler(v) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		key, ok := ref.KeyOf(v)
+		if false == ok {
+			return nil
+		}
+		if _, ok := w.done[key]; true == ok {
+			return nil
+		}
+		g := f.Hare(ref.Step{Key: key, Path: path})
+		defer g.Clear()
+		if false == g.Ok() {
+			return &CycleError{
+				Path:   path,
+				Target: g.Cycle()[0].(ref.Step).Path,
+				Type:   key.Type,
+				value:  v,
+			}
+		}
+		if err := w.walkRef(g, v, path); nil != err {
+			return err
+		}
+		w.done[key] = struct{}{}
+	case reflect.Interface:
+		if false == v.IsNil() {
+			return w.walk(f, v.Elem(), path)
+		}
+	case reflect.Struct:
+		return w.walkStruct(f, v, path)
+	case reflect.Array:
+		return w.walkElems(f, v, path)
+	}
+	return nil
+}
+
+// The walkRef method walks whatever the reference v refers to, after it has been passed to f.
+func (w *walker) walkRef(f floyds.BranchingDetector, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		return w.walk(f, v.Elem(), path)
+	case reflect.Map:
+		entries, ok := jsonrules.MapEntries(v)
+		if false == ok {
+			// encoding/json will fail, anyway
+			return nil
+		}
+		for _, e := range entries {
+			if err := w.walk(f, e.Value, path+formatName(e.Name)); nil != err {
+				return err
+			}
+		}
+		return nil
+	default:
+		return w.walkElems(f, v, path)
+	}
+}
+
+// The walkStruct method walks the fields of the struct v, that would be encoded by encoding/json.
+func (w *walker) walkStruct(f floyds.BranchingDetector, v reflect.Value, path string) error {
+	for _, field := range jsonrules.TypeFields(v.Type()) {
+		fv, ok := jsonrules.FieldByIndex(v, field.Index)
+		if false == ok || jsonrules.IsOmitted(field, fv) {
+			continue
+		}
+		if err := w.walk(f, fv, path+formatName(field.Name)); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// The walkElems method walks the elements of the slice or array v.
+func (w *walker) walkElems(f floyds.BranchingDetector, v reflect.Value, path string) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := w.walk(f, v.Index(i), path+"["+strconv.Itoa(i)+"]"); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// The formatName function formats an object member name, as a JSON path segment.
+func formatName(name string) string {
+	if isIdentifier(name) {
+		return "." + name
+	}
+	return "[" + strconv.Quote(name) + "]"
+}
+
+// The isIdentifier function returns true if name may be used with dot notation, in a JSON path.
+func isIdentifier(name string) bool {
+	if "" == name {
+		return false
+	}
+	for i, c := range name {
+		if '_' == c || '$' == c || unicode.IsLetter(c) || (0 != i && unicode.IsDigit(c)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
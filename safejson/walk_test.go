@@ -0,0 +1,216 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package safejson
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type (
+	testSkipped struct {
+		Ignored  *testSkipped `json:"-"`
+		Empty    *testSkipped `json:",omitempty"`
+		Zero     testZero     `json:",omitzero"`
+		private  *testSkipped
+		Included *testSkipped `json:"-,"`
+	}
+
+	testZero struct {
+		Self  *testSkipped
+		Count int
+	}
+
+	testMarshaler struct {
+		Self *testMarshaler
+	}
+
+	testPtrMarshaler struct {
+		Self *testPtrMarshaler
+	}
+
+	testText struct {
+		Self *testText
+	}
+
+	testKey struct {
+		name string
+	}
+
+	testEmbedded struct {
+		*testInner
+		Other *testEmbedded `json:"other"`
+	}
+
+	testInner struct {
+		Next *testEmbedded `json:"next"`
+	}
+
+	testConflict struct {
+		testConflictA
+		testConflictB
+	}
+
+	testConflictA struct {
+		Self *testConflict
+	}
+
+	testConflictB struct {
+		Self *testConflict
+	}
+)
+
+func (testZero) IsZero() bool { return true }
+
+func (m testMarshaler) MarshalJSON() ([]byte, error) { return []byte(`null`), nil }
+
+func (m *testPtrMarshaler) MarshalJSON() ([]byte, error) { return []byte(`null`), nil }
+
+func (m testText) MarshalText() ([]byte, error) { return []byte(`text`), nil }
+
+func (k testKey) MarshalText() ([]byte, error) { return []byte(k.name), nil }
+
+func checkPath(t *testing.T, v interface{}, path, target string) {
+	t.Helper()
+	err := Check(v)
+	if "" == path {
+		if nil != err {
+			t.Error(err)
+		}
+		if _, err := json.Marshal(v); nil != err {
+			t.Error(err)
+		}
+		return
+	}
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) {
+		t.Error(err)
+		return
+	}
+	if path != cycleErr.Path || target != cycleErr.Target {
+		t.Error(cycleErr)
+	}
+}
+
+func TestCheck_tags(t *testing.T) {
+	v := &testSkipped{}
+	v.Ignored = v
+	v.Zero.Self = v
+	v.private = v
+	checkPath(t, v, "", "")
+	v.Empty = v
+	checkPath(t, v, "$.Empty", "$")
+	v.Empty = nil
+	v.Included = v
+	checkPath(t, v, `$["-"]`, "$")
+}
+
+func TestCheck_marshalers(t *testing.T) {
+	m := &testMarshaler{}
+	m.Self = m
+	checkPath(t, m, "", "")
+	// a pointer receiver is only used if the value is addressable
+	p := &testPtrMarshaler{}
+	p.Self = p
+	checkPath(t, p, "", "")
+	checkPath(t, []interface{}{testPtrMarshaler{Self: p}}, "", "")
+	q := &struct{ Self interface{} }{}
+	q.Self = []interface{}{testPtrMarshaler{}, q}
+	checkPath(t, q, "$.Self[1]", "$")
+	q.Self = []testPtrMarshaler{{}}
+	checkPath(t, q, "", "")
+	x := &testText{}
+	x.Self = x
+	checkPath(t, x, "", "")
+	checkPath(t, map[string]interface{}{"a": time.Time{}}, "", "")
+}
+
+func TestCheck_mapKeys(t *testing.T) {
+	m := map[testKey]interface{}{{"b"}: 1, {"a c"}: nil}
+	m[testKey{"a c"}] = m
+	checkPath(t, m, `$["a c"]`, "$")
+	n := map[int]interface{}{10: nil, 9: 1}
+	n[10] = []interface{}{n}
+	checkPath(t, n, `$["10"][0]`, "$")
+	// the order is the same as encoding/json, keys are sorted by their string representation
+	o := map[string]interface{}{"b": nil, "a": nil}
+	o["a"] = []interface{}{o}
+	o["b"] = o
+	checkPath(t, o, `$.a[0]`, "$")
+	// keys that can't be encoded are left to encoding/json
+	if err := Check(map[float64]interface{}{1: nil}); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestCheck_embedded(t *testing.T) {
+	e := &testEmbedded{testInner: &testInner{}}
+	e.Next = e
+	checkPath(t, e, "$.next", "$")
+	e.Next = nil
+	e.Other = &testEmbedded{}
+	e.Other.Other = e
+	checkPath(t, e, "$.other.other", "$")
+	// nil embedded pointers are skipped
+	checkPath(t, &testEmbedded{}, "", "")
+	// conflicting fields are not encoded
+	c := &testConflict{}
+	c.testConflictA.Self = c
+	c.testConflictB.Self = c
+	checkPath(t, c, "", "")
+}
+
+func TestCheck_slices(t *testing.T) {
+	s := make([]interface{}, 2)
+	s[1] = s
+	checkPath(t, s, "$[1]", "$")
+	a := [2]interface{}{}
+	a[1] = &a
+	checkPath(t, a, "$[1][1]", "$[1]")
+	checkPath(t, [][]byte{{1, 2}}, "", "")
+}
+
+func TestCheck_shared(t *testing.T) {
+	type level struct {
+		A, B *level
+	}
+	var root *level
+	for x := 0; x < 64; x++ {
+		root = &level{root, root}
+	}
+	if err := Check(root); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func Test_formatName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"a":     ".a",
+		"_a1$":  "._a1$",
+		"1a":    `["1a"]`,
+		"":      `[""]`,
+		"a b":   `["a b"]`,
+		"a\"b":  `["a\"b"]`,
+		"héllo": ".héllo",
+	} {
+		if actual := formatName(name); actual != expected {
+			t.Error(name, actual)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package structural
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+	"github.com/joeycumines/go-detect-cycle/internal/ref"
+)
+
+type (
+	// The differ struct holds the state for a single call to Diff.
+	differ struct {
+		changes []Change
+		// done contains every pair of references that has been diffed in it's entirety, without encountering any
+		// back-edges, meaning the result does not depend on the path, and there is no need to diff them again.
+		done map[[2]ref.Key]struct{}
+	}
+)
+
+// Diff returns the changes required to get from a to b, or nil if they are structurally equal, see also the package
+// documentation. Every change is addressed by it's path, from the root value, using the same syntax as Go, like
+// `.Spec.Children[3].Name` (pointers are dereferenced implicitly), and the values at any path where the types,
+// nil-ness, back-edges, or scalar values differ are reported as Modified, while map entries and slice elements that
+// are only present in one of the values are reported as Added or Removed. Changes are ordered by their position,
+// with struct fields in declaration order, and map entries sorted by their formatted keys (entries only present in b
+// are reported last), so the result is stable. Note that each pair of references (that didn't contain back-edges) is
+// only diffed once, meaning any changes within a shared reference are reported only at the first path it was found.
+func Diff(a, b interface{}) []Change {
+	d := differ{done: make(map[[2]ref.Key]struct{})}
+	d.diff(
+		floyds.NewExactBranchingDetector(ref.Step{}, ref.StepKey),
+		floyds.NewExactBranchingDetector(ref.Step{}, ref.StepKey),
+		reflect.ValueOf(a),
+		reflect.ValueOf(b),
+		"",
+	)
+	return d.changes
+}
+
+func (d *differ) add(path string, t ChangeType, from, to reflect.Value) {
+	d.changes = append(d.changes, Change{
+		Path: path,
+		Type: t,
+		From: from,
+		To:   to,
+	})
+}
+
+// The diff method diffs a and b, which are at path, returning true if any back-edges were encountered.
+func (d *differ) diff(fa, fb floyds.BranchingDetector, a, b reflect.Value, path string) bool {
+	if false == a.IsValid() || false == b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.add(path, Modified, a, b)
+		}
+		return false
+	}
+	if a.Type() != b.Type() {
+		d.add(path, Modified, a, b)
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Modified, a, b)
+			}
+			return false
+		}
+		ka, oka := ref.KeyOf(a)
+		kb, okb := ref.KeyOf(b)
+		if false == oka || false == okb {
+			// at least one is an empty slice
+			return d.diffElems(fa, fb, a, b, path)
+		}
+		pair := [2]ref.Key{ka, kb}
+		if _, ok := d.done[pair]; true == ok {
+			return false
+		}
+		ga, gb := fa.Hare(ref.Step{Key: ka}), fb.Hare(ref.Step{Key: kb})
+		defer ga.Clear()
+		defer gb.Clear()
+		da, backA := backEdge(ga)
+		db, backB := backEdge(gb)
+		if true == backA || true == backB {
+			if backA != backB || da != db {
+				d.add(path, Modified, a, b)
+			}
+			return true
+		}
+		var back bool
+		switch a.Kind() {
+		case reflect.Ptr:
+			back = d.diff(ga, gb, a.Elem(), b.Elem(), path)
+		case reflect.Map:
+			back = d.diffMap(ga, gb, a, b, path)
+		default:
+			back = d.diffElems(ga, gb, a, b, path)
+		}
+		if false == back {
+			d.done[pair] = struct{}{}
+		}
+		return back
+
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, Modified, a, b)
+			}
+			return false
+		}
+		return d.diff(fa, fb, a.Elem(), b.Elem(), path)
+
+	case reflect.Array:
+		return d.diffElems(fa, fb, a, b, path)
+
+	case reflect.Struct:
+		var (
+			t    = a.Type()
+			back bool
+		)
+		for i := 0; i < t.NumField(); i++ {
+			if d.diff(fa, fb, a.Field(i), b.Field(i), path+"."+t.Field(i).Name) {
+				back = true
+			}
+		}
+		return back
+
+	default:
+		if false == equalScalar(a, b) {
+			d.add(path, Modified, a, b)
+		}
+		return false
+	}
+}
+
+// The diffMap method diffs the maps a and b, which are the same type.
+func (d *differ) diffMap(fa, fb floyds.BranchingDetector, a, b reflect.Value, path string) bool {
+	var back bool
+	for _, k := range ref.MapKeys(a) {
+		p := path + "[" + ref.FormatKey(k) + "]"
+		if v := b.MapIndex(k); v.IsValid() {
+			if d.diff(fa, fb, a.MapIndex(k), v, p) {
+				back = true
+			}
+		} else {
+			d.add(p, Removed, a.MapIndex(k), reflect.Value{})
+		}
+	}
+	for _, k := range ref.MapKeys(b) {
+		if false == a.MapIndex(k).IsValid() {
+			d.add(path+"["+ref.FormatKey(k)+"]", Added, reflect.Value{}, b.MapIndex(k))
+		}
+	}
+	return back
+}
+
+// The diffElems method diffs the slices or arrays a and b, which are the same type, comparing elements by index.
+func (d *differ) diffElems(fa, fb floyds.BranchingDetector, a, b reflect.Value, path string) bool {
+	var back bool
+	for i := 0; i < a.Len() || i < b.Len(); i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= b.Len():
+			d.add(p, Removed, a.Index(i), reflect.Value{})
+		case i >= a.Len():
+			d.add(p, Added, reflect.Value{}, b.Index(i))
+		case d.diff(fa, fb, a.Index(i), b.Index(i), p):
+			back = true
+		}
+	}
+	return back
+}
+
+// The equalScalar function compares a and b, which are the same type, and not a kind that is handled by differ.diff,
+// behaving like reflect.DeepEqual, meaning funcs are equal only if they are both nil.
+func equalScalar(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Func:
+		return a.IsNil() && b.IsNil()
+	case reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,231 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package structural
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The formatChanges function formats changes, one per line, using Change.String.
+func formatChanges(changes []Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestDiff_equal(t *testing.T) {
+	shared := &testNode{Name: "shared"}
+	for i, pair := range [][2]interface{}{
+		{nil, nil},
+		{1, 1},
+		{(*testNode)(nil), (*testNode)(nil)},
+		{[]int{}, []int{}},
+		{[]*testNode{shared, shared}, []*testNode{{Name: "shared"}, {Name: "shared"}}},
+		{testTree(), testTree()},
+		{testRing("a", "b", "c"), testRing("a", "b", "c")},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"b": 2, "a": 1}},
+		{testSharedDAG(64), testSharedDAG(64)},
+	} {
+		if changes := Diff(pair[0], pair[1]); nil != changes {
+			t.Error(i, formatChanges(changes))
+		}
+		if Hash(pair[0]) != Hash(pair[1]) {
+			t.Error(i)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for _, tc := range []struct {
+		Name    string
+		A, B    interface{}
+		Changes string
+	}{
+		{
+			Name:    "root type",
+			A:       1,
+			B:       "1",
+			Changes: "~ (root): 1 → 1\n",
+		},
+		{
+			Name:    "root nil",
+			A:       nil,
+			B:       1,
+			Changes: "~ (root): <nil> → 1\n",
+		},
+		{
+			Name: "tree",
+			A:    testTree(),
+			B: func() *testNode {
+				v := testTree()
+				v.Children[1].Children[0].Name = "x"
+				v.Children = v.Children[:2]
+				return v
+			}(),
+			Changes: "~ .Children[1].Children[0].Name: b1 → x\n" +
+				"- .Children[2]: &{c [&{<max depth>}] &{root [<max depth>] <nil> <nil>} <nil>}\n",
+		},
+		{
+			Name: "map",
+			A:    map[string]int{"a": 1, "b": 2, "c": 3},
+			B:    map[string]int{"a": 1, "b": 3, "d": 4},
+			Changes: "~ [\"b\"]: 2 → 3\n" +
+				"- [\"c\"]: 3\n" +
+				"+ [\"d\"]: 4\n",
+		},
+		{
+			Name:    "slice",
+			A:       []interface{}{1, "a", []int{}},
+			B:       []interface{}{1, 2, []int{1}, nil},
+			Changes: "~ [1]: a → 2\n+ [2][0]: 1\n+ [3]: <nil>\n",
+		},
+		{
+			Name:    "nil slice",
+			A:       []int(nil),
+			B:       []int{},
+			Changes: "~ (root): [] → []\n",
+		},
+		{
+			Name:    "ring",
+			A:       testRing("a", "b"),
+			B:       testRing("a", "b", "c"),
+			Changes: "~ .Parent.Parent: &{a [] &{b [] <cycle → (root)> <nil>} <nil>} → &{c [] &{a [] &{<max depth>} <nil>} <nil>}\n",
+		},
+		{
+			Name: "back-edge",
+			A:    testTree(),
+			B: func() *testNode {
+				v := testTree()
+				v.Children[0].Children[0].Parent = v
+				return v
+			}(),
+			Changes: "~ .Children[0].Children[0].Parent: &{a [&{<max depth>}] &{root [<max depth>] <nil> <nil>} <nil>} → " +
+				"&{root [&{<max depth>} &{<max depth>} &{<max depth>}] <nil> <nil>}\n",
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			if changes := formatChanges(Diff(tc.A, tc.B)); changes != tc.Changes {
+				t.Errorf("unexpected changes:\n%s\nexpected:\n%s", changes, tc.Changes)
+			}
+		})
+	}
+}
+
+func TestDiff_values(t *testing.T) {
+	a := &testNode{Name: "a", next: &testNode{Name: "b"}}
+	b := &testNode{Name: "a"}
+	changes := Diff(a, b)
+	if 1 != len(changes) {
+		t.Fatal(formatChanges(changes))
+	}
+	c := changes[0]
+	if ".next" != c.Path || Modified != c.Type || c.From.Pointer() != reflect.ValueOf(a.next).Pointer() ||
+		false == c.To.IsNil() {
+		t.Fatal(c)
+	}
+	changes = Diff(struct{ F func() }{}, struct{ F func() }{func() {}})
+	if 1 != len(changes) || ".F" != changes[0].Path || Modified != changes[0].Type || false == changes[0].From.IsNil() {
+		t.Fatal(changes)
+	}
+	if changes = Diff(struct{ F func() }{func() {}}, struct{ F func() }{func() {}}); 1 != len(changes) {
+		t.Fatal(changes)
+	}
+	changes = Diff([]int{1}, []int{})
+	if 1 != len(changes) || "[0]" != changes[0].Path || Removed != changes[0].Type ||
+		1 != changes[0].From.Interface() || changes[0].To.IsValid() {
+		t.Fatal(changes)
+	}
+	changes = Diff([]int{}, []int{1})
+	if 1 != len(changes) || Added != changes[0].Type || changes[0].From.IsValid() || 1 != changes[0].To.Interface() {
+		t.Fatal(changes)
+	}
+}
+
+func TestDiff_shared(t *testing.T) {
+	a, b := testSharedDAG(64), testSharedDAG(64)
+	b.Right.Right.Value = -1
+	// only reported once, at the first path
+	if changes := formatChanges(Diff(a, b)); changes != "~ .Left.Left.Value: 61 → -1\n" {
+		t.Fatal(changes)
+	}
+}
+
+// The randomValue function generates a random (acyclic) value, for comparison against reflect.DeepEqual.
+func randomValue(depth int) interface{} {
+	if depth <= 0 {
+		switch rand.Intn(3) {
+		case 0:
+			return rand.Intn(3)
+		case 1:
+			return fmt.Sprint(rand.Intn(3))
+		default:
+			return nil
+		}
+	}
+	switch rand.Intn(4) {
+	case 0:
+		v := make([]interface{}, rand.Intn(3))
+		for i := range v {
+			v[i] = randomValue(depth - 1)
+		}
+		return v
+	case 1:
+		v := make(map[string]interface{})
+		for i := rand.Intn(3); i > 0; i-- {
+			v[fmt.Sprint(rand.Intn(3))] = randomValue(depth - 1)
+		}
+		return v
+	case 2:
+		return &testNode{Name: fmt.Sprint(rand.Intn(2)), Parent: &testNode{Name: fmt.Sprint(rand.Intn(2))}}
+	default:
+		return randomValue(0)
+	}
+}
+
+func TestDiff_deepEqual(t *testing.T) {
+	rand.Seed(8812834)
+	var equal int
+	for i := 0; i < 5000; i++ {
+		a, b := randomValue(3), randomValue(3)
+		changes := Diff(a, b)
+		if (nil == changes) != reflect.DeepEqual(a, b) {
+			t.Fatal(a, b, formatChanges(changes))
+		}
+		if nil == changes {
+			equal++
+			if Hash(a) != Hash(b) {
+				t.Fatal(a, b)
+			}
+		}
+	}
+	if equal < 100 {
+		t.Fatal(equal)
+	}
+}
+
+func TestChangeType_String(t *testing.T) {
+	if s := fmt.Sprint(Modified, Added, Removed, ChangeType(7)); s != "modified added removed ChangeType(7)" {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,238 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package structural
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+	"github.com/joeycumines/go-detect-cycle/internal/ref"
+)
+
+type (
+	// The hasher struct holds the state for a single call to Hash.
+	hasher struct {
+		// memo contains the hash of every reference, that did not contain any back-edges, so that shared references
+		// are only hashed once.
+		memo map[ref.Key]uint64
+	}
+
+	// The node struct builds the hash of a single value.
+	node struct {
+		h   hash.Hash64
+		buf [8]byte
+	}
+)
+
+// The tag constants are written as the first byte of the hash of each kind of value.
+const (
+	tagInvalid byte = iota
+	tagNil
+	tagBackEdge
+	tagPtr
+	tagMap
+	tagSlice
+	tagArray
+	tagStruct
+	tagInterface
+	tagScalar
+	tagFunc
+)
+
+// Hash returns a structural hash of v, which is deterministic, and equal for any two values that Diff reports no
+// changes for (although the reverse is not necessarily true), see also the package documentation.
+func Hash(v interface{}) uint64 {
+	h := hasher{memo: make(map[ref.Key]uint64)}
+	rv := reflect.ValueOf(v)
+	n := newNode(tagInterface)
+	if rv.IsValid() {
+		n.writeString(rv.Type().String())
+	}
+	sum, _ := h.hash(floyds.NewExactBranchingDetector(ref.Step{}, ref.StepKey), rv)
+	n.writeUint64(sum)
+	return n.sum()
+}
+
+func newNode(tag byte) *node {
+	n := &node{h: fnv.New64a()}
+	_, _ = n.h.Write([]byte{tag})
+	return n
+}
+
+func (n *node) writeUint64(v uint64) {
+	binary.LittleEndian.PutUint64(n.buf[:], v)
+	_, _ = n.h.Write(n.buf[:])
+}
+
+func (n *node) writeString(s string) {
+	n.writeUint64(uint64(len(s)))
+	_, _ = n.h.Write([]byte(s))
+}
+
+func (n *node) sum() uint64 {
+	return n.h.Sum64()
+}
+
+// The hash method returns the hash of v, and true if it contains any back-edges.
+func (h *hasher) hash(f floyds.BranchingDetector, v reflect.Value) (uint64, bool) {
+	if false == v.IsValid() {
+		return newNode(tagInvalid).sum(), false
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return newNode(tagNil).sum(), false
+		}
+		key, ok := ref.KeyOf(v)
+		if false == ok {
+			// an empty slice
+			return newNode(tagSlice).sum(), false
+		}
+		if sum, ok := h.memo[key]; true == ok {
+			return sum, false
+		}
+		g := f.Hare(ref.Step{Key: key})
+		defer g.Clear()
+		if distance, ok := backEdge(g); true == ok {
+			n := newNode(tagBackEdge)
+			n.writeUint64(uint64(distance))
+			return n.sum(), true
+		}
+		var (
+			sum  uint64
+			back bool
+		)
+		switch v.Kind() {
+		case reflect.Ptr:
+			n := newNode(tagPtr)
+			s, b := h.hash(g, v.Elem())
+			n.writeUint64(s)
+			sum, back = n.sum(), b
+		case reflect.Map:
+			sum, back = h.hashMap(g, v)
+		default:
+			sum, back = h.hashElems(g, v, tagSlice)
+		}
+		if false == back {
+			h.memo[key] = sum
+		}
+		return sum, back
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return newNode(tagNil).sum(), false
+		}
+		n := newNode(tagInterface)
+		n.writeString(v.Elem().Type().String())
+		s, back := h.hash(f, v.Elem())
+		n.writeUint64(s)
+		return n.sum(), back
+
+	case reflect.Array:
+		return h.hashElems(f, v, tagArray)
+
+	case reflect.Struct:
+		n := newNode(tagStruct)
+		var back bool
+		for i := 0; i < v.NumField(); i++ {
+			s, b := h.hash(f, v.Field(i))
+			n.writeUint64(s)
+			back = back || b
+		}
+		return n.sum(), back
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		n := newNode(tagFunc)
+		if 0 == v.Pointer() {
+			n.writeUint64(0)
+		} else {
+			n.writeUint64(1)
+		}
+		return n.sum(), false
+
+	default:
+		n := newNode(tagScalar)
+		switch v.Kind() {
+		case reflect.Bool:
+			if v.Bool() {
+				n.writeUint64(1)
+			} else {
+				n.writeUint64(0)
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n.writeUint64(uint64(v.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n.writeUint64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			n.writeUint64(floatBits(v.Float()))
+		case reflect.Complex64, reflect.Complex128:
+			n.writeUint64(floatBits(real(v.Complex())))
+			n.writeUint64(floatBits(imag(v.Complex())))
+		case reflect.String:
+			n.writeString(v.String())
+		}
+		return n.sum(), false
+	}
+}
+
+// The hashMap method hashes the map v, independently of the order of it's entries.
+func (h *hasher) hashMap(f floyds.BranchingDetector, v reflect.Value) (uint64, bool) {
+	var (
+		sum  uint64
+		back bool
+		iter = v.MapRange()
+	)
+	for iter.Next() {
+		ks, kb := h.hash(f, iter.Key())
+		vs, vb := h.hash(f, iter.Value())
+		e := newNode(tagMap)
+		e.writeUint64(ks)
+		e.writeUint64(vs)
+		sum += e.sum()
+		back = back || kb || vb
+	}
+	n := newNode(tagMap)
+	n.writeUint64(uint64(v.Len()))
+	n.writeUint64(sum)
+	return n.sum(), back
+}
+
+// The hashElems method hashes the slice or array v.
+func (h *hasher) hashElems(f floyds.BranchingDetector, v reflect.Value, tag byte) (uint64, bool) {
+	n := newNode(tag)
+	n.writeUint64(uint64(v.Len()))
+	var back bool
+	for i := 0; i < v.Len(); i++ {
+		s, b := h.hash(f, v.Index(i))
+		n.writeUint64(s)
+		back = back || b
+	}
+	return n.sum(), back
+}
+
+// The floatBits function returns the bits of f, treating negative zero as zero, since they are equal.
+func floatBits(f float64) uint64 {
+	if 0 == f {
+		return 0
+	}
+	return math.Float64bits(f)
+}
@@ -0,0 +1,186 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package structural
+
+import (
+	"math"
+	"testing"
+)
+
+type (
+	testNode struct {
+		Name     string
+		Children []*testNode
+		Parent   *testNode
+		next     *testNode
+	}
+
+	testDAG struct {
+		Left, Right *testDAG
+		Value       int
+	}
+)
+
+// The testRing function returns a ring of nodes, with the given names, linked via Parent.
+func testRing(names ...string) *testNode {
+	nodes := make([]*testNode, len(names))
+	for i, name := range names {
+		nodes[i] = &testNode{Name: name}
+	}
+	for i, n := range nodes {
+		n.Parent = nodes[(i+1)%len(nodes)]
+	}
+	return nodes[0]
+}
+
+// The testTree function returns a small tree, with parent back-references.
+func testTree() *testNode {
+	root := &testNode{Name: "root"}
+	for _, name := range []string{"a", "b", "c"} {
+		child := &testNode{Name: name, Parent: root}
+		child.Children = []*testNode{{Name: name + "1", Parent: child}}
+		root.Children = append(root.Children, child)
+	}
+	return root
+}
+
+// The testSharedDAG function returns a DAG of the given depth, where both children of each node are the same node,
+// meaning it has 2^depth paths.
+func testSharedDAG(depth int) *testDAG {
+	d := &testDAG{}
+	for i := 0; i < depth; i++ {
+		d = &testDAG{Left: d, Right: d, Value: i}
+	}
+	return d
+}
+
+func TestHash_equal(t *testing.T) {
+	shared := &testNode{Name: "shared"}
+	for i, pair := range [][2]interface{}{
+		{nil, nil},
+		{1, 1},
+		{"a", "a"},
+		{0.0, math.Copysign(0, -1)},
+		{(*testNode)(nil), (*testNode)(nil)},
+		{&testNode{Name: "a"}, &testNode{Name: "a"}},
+		{[]*testNode{shared, shared}, []*testNode{{Name: "shared"}, {Name: "shared"}}},
+		{testTree(), testTree()},
+		{testRing("a"), testRing("a")},
+		{testRing("a", "b", "c"), testRing("a", "b", "c")},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"b": 2, "a": 1}},
+		{[]interface{}{1, "a", nil}, []interface{}{1, "a", nil}},
+		{struct{ F func() }{func() {}}, struct{ F func() }{func() {}}},
+		{testSharedDAG(5), testSharedDAG(5)},
+	} {
+		if a, b := Hash(pair[0]), Hash(pair[1]); a != b {
+			t.Error(i, a, b)
+		}
+		if a, b := Hash(pair[0]), Hash(pair[0]); a != b {
+			t.Error(i, a, b)
+		}
+	}
+}
+
+func TestHash_notEqual(t *testing.T) {
+	tree := testTree()
+	tree.Children[1].Children[0].Name = "x"
+	for i, pair := range [][2]interface{}{
+		{nil, 0},
+		{1, 2},
+		{1, int64(1)},
+		{"a", "b"},
+		{[]int(nil), []int{}},
+		{[]int{1, 2}, []int{2, 1}},
+		{[]int{1}, [1]int{1}},
+		{(*testNode)(nil), &testNode{}},
+		{testTree(), tree},
+		{testRing("a"), testRing("a", "a")},
+		{testRing("a", "a"), testRing("a", "a", "a")},
+		{testRing("a", "b"), testRing("b", "a")},
+		{map[string]int{"a": 1}, map[string]int{"a": 2}},
+		{map[string]int{"a": 1}, map[string]int{"b": 1}},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"a": 2, "b": 1}},
+		{[]interface{}{1}, []interface{}{int64(1)}},
+		{struct{ F func() }{}, struct{ F func() }{func() {}}},
+		{testSharedDAG(5), testSharedDAG(6)},
+	} {
+		if a, b := Hash(pair[0]), Hash(pair[1]); a == b {
+			t.Error(i, a)
+		}
+	}
+}
+
+func TestHash_cycleShape(t *testing.T) {
+	// a node whose parent is itself has the same shape as any other
+	a := &testNode{Name: "a"}
+	a.Parent = a
+	b := &testNode{Name: "a"}
+	b.Parent = b
+	if Hash(a) != Hash(b) {
+		t.Fatal()
+	}
+	// but not the same shape as a node whose parent refers back to it
+	c := &testNode{Name: "a", Parent: &testNode{Name: "a"}}
+	c.Parent.Parent = c
+	if Hash(a) == Hash(c) {
+		t.Fatal()
+	}
+	// a back-edge to a different ancestor
+	x, y := testTree(), testTree()
+	y.Children[0].Children[0].Parent = y
+	if Hash(x) == Hash(y) {
+		t.Fatal()
+	}
+}
+
+func TestHash_sharedDAG(t *testing.T) {
+	// would take 2^64 steps, without memoizing shared references
+	a, b := testSharedDAG(64), testSharedDAG(64)
+	if Hash(a) != Hash(b) {
+		t.Fatal()
+	}
+	b.Right.Right.Value = -1
+	if Hash(a) == Hash(b) {
+		t.Fatal()
+	}
+}
+
+func TestHash_mapOrder(t *testing.T) {
+	a := make(map[int]string)
+	b := make(map[int]string)
+	for i := 0; i < 100; i++ {
+		a[i] = string(rune('a' + i%26))
+		b[99-i] = string(rune('a' + (99-i)%26))
+	}
+	if Hash(a) != Hash(b) {
+		t.Fatal()
+	}
+}
+
+func TestHash_cyclicMap(t *testing.T) {
+	a := map[string]interface{}{"name": "a"}
+	a["self"] = a
+	b := map[string]interface{}{"name": "a"}
+	b["self"] = b
+	if Hash(a) != Hash(b) {
+		t.Fatal()
+	}
+	b["name"] = "b"
+	if Hash(a) == Hash(b) {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,114 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package structural provides structural hashing and diffing of arbitrary Go values, using reflect, which are safe to
+// use with cyclic values, using the exact mode of floyds.BranchingDetector.
+//
+// Values are compared by their structure and content, like reflect.DeepEqual, including unexported fields, rather
+// than by the identity of any pointers, maps, or slices. Any reference that refers back to one of it's ancestors (a
+// back-edge, forming a cycle) is compared by it's relative position, the number of references between it and that
+// ancestor, meaning two cyclic values are equal if they have the same "shape", regardless of their addresses. Like
+// reflect.DeepEqual, funcs are equal only if they are both nil, and channels (and unsafe pointers) are compared by
+// their identity, although only whether they are nil contributes to the hash, so that it remains deterministic.
+package structural
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/joeycumines/go-detect-cycle/floyds"
+	"github.com/joeycumines/go-detect-cycle/pretty"
+)
+
+type (
+	// ChangeType is the type of a Change.
+	ChangeType int
+
+	// Change models a single difference between two values, as found by Diff.
+	Change struct {
+		// Path is the location of the change, like `.Spec.Children[3].Name`, where the empty string is the root.
+		Path string
+		// Type indicates whether the value at Path was added, removed, or modified.
+		Type ChangeType
+		// From is the value at Path, in the first value, which will be invalid (the zero value) if it was added.
+		From reflect.Value
+		// To is the value at Path, in the second value, which will be invalid (the zero value) if it was removed.
+		To reflect.Value
+	}
+)
+
+const (
+	// Modified indicates that the value at the path is different, but present in both values.
+	Modified ChangeType = iota
+	// Added indicates a map entry, or slice element, that is only present in the second value.
+	Added
+	// Removed indicates a map entry, or slice element, that is only present in the first value.
+	Removed
+)
+
+// The formatter var is used to format the values of a Change, which may be cyclic.
+var formatter = pretty.Config{MaxDepth: 2, MaxWidth: 8}
+
+// String implements fmt.Stringer.
+func (t ChangeType) String() string {
+	switch t {
+	case Modified:
+		return "modified"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return fmt.Sprintf("ChangeType(%d)", int(t))
+	}
+}
+
+// String implements fmt.Stringer, formatting the change, and any values, which are truncated if they are large.
+func (c Change) String() string {
+	path := c.Path
+	if "" == path {
+		path = "(root)"
+	}
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", path, formatValue(c.To))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", path, formatValue(c.From))
+	default:
+		return fmt.Sprintf("~ %s: %s → %s", path, formatValue(c.From), formatValue(c.To))
+	}
+}
+
+// The formatValue function formats a value for Change.String.
+func formatValue(v reflect.Value) string {
+	if false == v.IsValid() {
+		return "<nil>"
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%v", formatter.Formatter(v.Interface()))
+	}
+	// obtained via an unexported field, which fmt will print without following any nested pointers
+	return fmt.Sprint(v)
+}
+
+// The backEdge function returns the relative position of the reference that g revisited, as the number of references
+// between it and the ancestor, if g is not ok.
+func backEdge(g floyds.BranchingDetector) (int, bool) {
+	if true == g.Ok() {
+		return 0, false
+	}
+	return len(g.Cycle()) - 1, true
+}
@@ -0,0 +1,133 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package tarjan provides Tarjan's strongly connected components algorithm, for whole-graph cycle analysis, where the
+// floyds package only considers the cycles along a single path. Every cycle in a graph is contained within a single
+// strongly connected component, meaning a graph is acyclic if and only if every component is a single node, without
+// an edge to itself, see also Condensation.Cyclic.
+//
+// The implementation is iterative, rather than recursive, so it's safe to use with deep graphs.
+package tarjan
+
+import (
+	"sort"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+// Condensation models the strongly connected components of a graph, and is itself a graph.Graph, that is always
+// acyclic (a DAG), where each node is the index of a component (an int), with an edge between two components if
+// there is an edge between any of their nodes. The zero value is an empty graph.
+type Condensation struct {
+	components [][]interface{}
+	index      map[interface{}]int
+	nodes      []interface{}
+	successors [][]interface{}
+	cyclic     []bool
+}
+
+// Components returns the strongly connected components of g, in reverse topological order, meaning every edge
+// between two components is from a later component to an earlier one (e.g. a component with no edges to other
+// components, a sink, will be first). The nodes within each component are in the order they were discovered, by a
+// depth first search, started from each node, in order, meaning the result is deterministic.
+func Components(g graph.Graph) [][]interface{} {
+	return Condense(g).Components()
+}
+
+// Condense returns the Condensation of g, see also Components.
+func Condense(g graph.Graph) Condensation {
+	d := digraph.New(g)
+	components := d.Components(nil)
+	c := Condensation{
+		components: make([][]interface{}, len(components)),
+		index:      make(map[interface{}]int, len(d.Nodes)),
+		nodes:      make([]interface{}, len(components)),
+		successors: make([][]interface{}, len(components)),
+		cyclic:     make([]bool, len(components)),
+	}
+	component := make([]int, len(d.Nodes))
+	for i, nodes := range components {
+		c.components[i] = make([]interface{}, len(nodes))
+		for j, node := range nodes {
+			c.components[i][j] = d.Nodes[node]
+			c.index[d.Nodes[node]] = i
+			component[node] = i
+		}
+		c.nodes[i] = i
+		c.cyclic[i] = 1 != len(nodes) || d.HasSelfLoop(nodes[0])
+	}
+	for i, nodes := range components {
+		seen := make(map[int]struct{})
+		var successors []int
+		for _, node := range nodes {
+			for _, w := range d.Successors[node] {
+				if j := component[w]; j != i {
+					if _, ok := seen[j]; false == ok {
+						seen[j] = struct{}{}
+						successors = append(successors, j)
+					}
+				}
+			}
+		}
+		sort.Ints(successors)
+		for _, j := range successors {
+			c.successors[i] = append(c.successors[i], j)
+		}
+	}
+	return c
+}
+
+// Components returns the strongly connected components, see also the Components function, and must not be modified.
+func (c Condensation) Components() [][]interface{} {
+	return c.components
+}
+
+// Component returns the index of the component containing node, and true, or false if node is not in the graph.
+func (c Condensation) Component(node interface{}) (int, bool) {
+	i, ok := c.index[node]
+	return i, ok
+}
+
+// Cyclic returns true if the component at index i contains a cycle, meaning it has more than one node, or a single
+// node, with an edge to itself. It panics if i is out of range.
+func (c Condensation) Cyclic(i int) bool {
+	return c.cyclic[i]
+}
+
+// Acyclic returns true if none of the components contain a cycle, meaning the original graph is a DAG.
+func (c Condensation) Acyclic() bool {
+	for _, cyclic := range c.cyclic {
+		if true == cyclic {
+			return false
+		}
+	}
+	return true
+}
+
+// Nodes implements graph.Graph, returning the index of each component, in order, and must not be modified.
+func (c Condensation) Nodes() []interface{} {
+	return c.nodes
+}
+
+// Successors implements graph.Graph, returning the indexes of the components that the component, at index node (an
+// int), has an edge to, in ascending order (all of which will be less than node), and must not be modified.
+func (c Condensation) Successors(node interface{}) []interface{} {
+	if i, ok := node.(int); true == ok && 0 <= i && i < len(c.successors) {
+		return c.successors[i]
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tarjan
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+)
+
+// The randomGraph function generates a random graph, with n nodes, and m edges.
+func randomGraph(n, m int) *graph.Adjacency {
+	var g graph.Adjacency
+	for i := 0; i < n; i++ {
+		g.AddNode(i)
+	}
+	for i := 0; i < m; i++ {
+		g.AddEdge(rand.Intn(n), rand.Intn(n))
+	}
+	return &g
+}
+
+// The reachable function returns the set of nodes reachable from node, including itself.
+func reachable(g graph.Graph, node interface{}) map[interface{}]bool {
+	seen := map[interface{}]bool{node: true}
+	queue := []interface{}{node}
+	for 0 != len(queue) {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range g.Successors(v) {
+			if false == seen[w] {
+				seen[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+	return seen
+}
+
+func TestComponents(t *testing.T) {
+	var g graph.Adjacency
+	for _, e := range [][2]string{
+		{"a", "b"}, {"b", "c"}, {"c", "a"}, {"b", "d"}, {"d", "e"}, {"e", "d"}, {"f", "f"}, {"f", "a"},
+	} {
+		g.AddEdge(e[0], e[1])
+	}
+	g.AddNode("g")
+	if s := fmt.Sprint(Components(&g)); s != "[[d e] [a b c] [f] [g]]" {
+		t.Fatal(s)
+	}
+	c := Condense(&g)
+	s := fmt.Sprint(c.Nodes(), c.Successors(0), c.Successors(1), c.Successors(2), c.Successors(3))
+	if s != "[0 1 2 3] [] [0] [1] []" {
+		t.Fatal(s)
+	}
+	if false == c.Cyclic(0) || false == c.Cyclic(1) || false == c.Cyclic(2) || true == c.Cyclic(3) || true == c.Acyclic() {
+		t.Fatal(c.cyclic)
+	}
+	if i, ok := c.Component("e"); 0 != i || false == ok {
+		t.Fatal(i, ok)
+	}
+	if _, ok := c.Component("z"); true == ok {
+		t.Fatal()
+	}
+	if nil != c.Successors(4) || nil != c.Successors(-1) || nil != c.Successors("a") {
+		t.Fatal()
+	}
+}
+
+func TestCondense_acyclic(t *testing.T) {
+	var g graph.Adjacency
+	g.AddEdge(1, 2)
+	g.AddEdge(1, 3)
+	g.AddEdge(2, 3)
+	c := Condense(&g)
+	if false == c.Acyclic() || fmt.Sprint(c.Components()) != "[[3] [2] [1]]" {
+		t.Fatal(c.Components())
+	}
+	if false == (Condensation{}).Acyclic() || 0 != len(Components(&graph.Adjacency{})) {
+		t.Fatal()
+	}
+}
+
+func TestComponents_random(t *testing.T) {
+	rand.Seed(2391001)
+	for x := 0; x < 200; x++ {
+		g := randomGraph(1+rand.Intn(30), rand.Intn(60))
+		c := Condense(g)
+		reach := make(map[interface{}]map[interface{}]bool)
+		for _, node := range g.Nodes() {
+			reach[node] = reachable(g, node)
+		}
+		var count int
+		for i, component := range c.Components() {
+			count += len(component)
+			for _, a := range component {
+				for _, b := range g.Nodes() {
+					j, _ := c.Component(b)
+					// same component if and only if mutually reachable
+					if (i == j) != (reach[a][b] && reach[b][a]) {
+						t.Fatal(x, a, b)
+					}
+				}
+			}
+		}
+		if count != len(g.Nodes()) {
+			t.Fatal(x, count)
+		}
+		// reverse topological order
+		for _, a := range g.Nodes() {
+			for _, b := range g.Successors(a) {
+				i, _ := c.Component(a)
+				j, _ := c.Component(b)
+				if j > i {
+					t.Fatal(x, a, b)
+				}
+			}
+		}
+		// the condensation is itself acyclic
+		if false == Condense(c).Acyclic() || len(Components(c)) != len(c.Components()) {
+			t.Fatal(x)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package toposort
+
+import (
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+// CycleError models a cycle that prevented a graph from being sorted, and is returned by Sort and Levels. It's
+// always returned as a pointer, so it can be extracted, from any wrapping errors, using errors.As.
+type CycleError struct {
+	// Cycle contains the nodes that form the cycle, formatted like floyds.BranchingDetector.Cycle, starting and ending
+	// with the same node, e.g. `[a b c a]`, and is one of the shortest cycles in the graph.
+	Cycle []interface{}
+}
+
+// Error implements the error interface, describing the cycle, formatting each node using `%v`.
+func (e *CycleError) Error() string {
+	return digraph.FormatCycle("cycle detected: ", " -> ", e.Cycle)
+}
+
+// The newCycleError function returns a *CycleError for one of the shortest cycles, within the nodes that have a
+// non-zero inDegree (which could not be sorted).
+func newCycleError(d digraph.Graph, inDegree []int) *CycleError {
+	shortest := d.ShortestCycle(func(node int) bool { return 0 != inDegree[node] })
+	e := &CycleError{Cycle: make([]interface{}, len(shortest))}
+	for i, v := range shortest {
+		e.Cycle[i] = d.Nodes[v]
+	}
+	return e
+}
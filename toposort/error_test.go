@@ -0,0 +1,46 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package toposort
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+func TestCycleError_Error(t *testing.T) {
+	if s := (&CycleError{Cycle: []interface{}{1, "b", 1}}).Error(); s != "cycle detected: 1 -> b -> 1" {
+		t.Fatal(s)
+	}
+}
+
+func TestNewCycleError(t *testing.T) {
+	// two cycles of the same length, 0 1 2 0, and 3 4 5 3, with an edge between them, and 6 reachable from both
+	d := digraph.Graph{
+		Nodes:      []interface{}{"a", "b", "c", "d", "e", "f", "g"},
+		Successors: [][]int{{1}, {2}, {0, 3}, {4}, {5}, {3, 6}, nil},
+	}
+	// the first component found is the later one, since it's a sink
+	if s := fmt.Sprint(newCycleError(d, []int{1, 1, 1, 2, 1, 1, 2}).Cycle); s != "[d e f d]" {
+		t.Fatal(s)
+	}
+	// nodes with an in-degree of zero are excluded
+	if s := fmt.Sprint(newCycleError(d, []int{1, 1, 1, 0, 1, 1, 2}).Cycle); s != "[a b c a]" {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package toposort provides topological sorting of directed graphs, using Kahn's algorithm, where every node is
+// ordered before it's successors (e.g. for an edge from a dependency to each of it's dependents), with deterministic
+// tie-breaking, returning a *CycleError, describing one of the shortest cycles, if the graph is not acyclic.
+package toposort
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+type (
+	// Options configures Sort and Levels, the zero value being the default.
+	Options struct {
+		// Less breaks ties between nodes that are ready at the same time, ordering a before b if it returns true,
+		// and defaults to the order the nodes were returned by the graph (see also graph.Graph), if it's nil.
+		Less func(a, b interface{}) bool
+	}
+
+	// The ready struct implements heap.Interface, for the indexes of the nodes that are ready to be sorted.
+	ready struct {
+		nodes []int
+		less  func(a, b int) bool
+	}
+)
+
+// Sort is Options.Sort, using the default options.
+func Sort(g graph.Graph) ([]interface{}, error) {
+	return Options{}.Sort(g)
+}
+
+// Levels is Options.Levels, using the default options.
+func Levels(g graph.Graph) ([][]interface{}, error) {
+	return Options{}.Levels(g)
+}
+
+// Sort returns every node of g, in topological order, where ties are broken using Less, meaning the result is the
+// smallest possible order, as determined by Less, and is therefore deterministic. If g contains a cycle, a
+// *CycleError will be returned, describing one of the shortest cycles, along with the order of the nodes that could
+// be sorted (which does not include any nodes that are part of, or reachable from, a cycle).
+func (o Options) Sort(g graph.Graph) ([]interface{}, error) {
+	var (
+		d        = digraph.New(g)
+		inDegree = inDegrees(d)
+		r        = ready{less: o.less(d)}
+		order    = make([]interface{}, 0, len(d.Nodes))
+	)
+	for v, degree := range inDegree {
+		if 0 == degree {
+			r.nodes = append(r.nodes, v)
+		}
+	}
+	heap.Init(&r)
+	for 0 != r.Len() {
+		v := heap.Pop(&r).(int)
+		order = append(order, d.Nodes[v])
+		for _, w := range d.Successors[v] {
+			inDegree[w]--
+			if 0 == inDegree[w] {
+				heap.Push(&r, w)
+			}
+		}
+	}
+	if len(order) != len(d.Nodes) {
+		return order, newCycleError(d, inDegree)
+	}
+	return order, nil
+}
+
+// Levels returns every node of g, grouped into levels, where each node is in the level after the last level that
+// contains any of it's predecessors (or the first level, if it has none), meaning every node within a level is
+// independent of the others, e.g. they could be processed in parallel. The nodes within each level are sorted using
+// Less. If g contains a cycle, a *CycleError will be returned, describing one of the shortest cycles, along with the
+// levels that could be sorted (which do not include any nodes that are part of, or reachable from, a cycle).
+func (o Options) Levels(g graph.Graph) ([][]interface{}, error) {
+	var (
+		d        = digraph.New(g)
+		inDegree = inDegrees(d)
+		less     = o.less(d)
+		current  []int
+		levels   [][]interface{}
+		count    int
+	)
+	for v, degree := range inDegree {
+		if 0 == degree {
+			current = append(current, v)
+		}
+	}
+	for 0 != len(current) {
+		sort.SliceStable(current, func(i, j int) bool { return less(current[i], current[j]) })
+		level := make([]interface{}, len(current))
+		var next []int
+		for i, v := range current {
+			level[i] = d.Nodes[v]
+			for _, w := range d.Successors[v] {
+				inDegree[w]--
+				if 0 == inDegree[w] {
+					next = append(next, w)
+				}
+			}
+		}
+		levels = append(levels, level)
+		count += len(level)
+		current = next
+	}
+	if count != len(d.Nodes) {
+		return levels, newCycleError(d, inDegree)
+	}
+	return levels, nil
+}
+
+// The less method returns a less func, for node indexes, using Less, if it's non-nil.
+func (o Options) less(d digraph.Graph) func(a, b int) bool {
+	if nil == o.Less {
+		return func(a, b int) bool { return a < b }
+	}
+	return func(a, b int) bool { return o.Less(d.Nodes[a], d.Nodes[b]) }
+}
+
+// The inDegrees function returns the number of edges to each node, including any duplicates.
+func inDegrees(d digraph.Graph) []int {
+	inDegree := make([]int, len(d.Nodes))
+	for _, successors := range d.Successors {
+		for _, w := range successors {
+			inDegree[w]++
+		}
+	}
+	return inDegree
+}
+
+func (r *ready) Len() int { return len(r.nodes) }
+
+func (r *ready) Less(i, j int) bool { return r.less(r.nodes[i], r.nodes[j]) }
+
+func (r *ready) Swap(i, j int) { r.nodes[i], r.nodes[j] = r.nodes[j], r.nodes[i] }
+
+func (r *ready) Push(x interface{}) { r.nodes = append(r.nodes, x.(int)) }
+
+func (r *ready) Pop() interface{} {
+	v := r.nodes[len(r.nodes)-1]
+	r.nodes = r.nodes[:len(r.nodes)-1]
+	return v
+}
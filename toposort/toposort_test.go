@@ -0,0 +1,153 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package toposort
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+)
+
+// The edges function returns a graph with the given edges, of the form "ab", for an edge from a to b.
+func edges(nodes string, edges ...string) *graph.Adjacency {
+	var g graph.Adjacency
+	for _, v := range nodes {
+		g.AddNode(string(v))
+	}
+	for _, e := range edges {
+		g.AddEdge(e[:1], e[1:])
+	}
+	return &g
+}
+
+func reverse(a, b interface{}) bool {
+	return a.(string) > b.(string)
+}
+
+func TestSort(t *testing.T) {
+	g := edges("abcdef", "ca", "cb", "ad", "bd", "ed", "ca")
+	if order, err := Sort(g); nil != err || fmt.Sprint(order) != "[c a b e d f]" {
+		t.Fatal(order, err)
+	}
+	if order, err := (Options{Less: reverse}).Sort(g); nil != err || fmt.Sprint(order) != "[f e c b a d]" {
+		t.Fatal(order, err)
+	}
+	if order, err := Sort(&graph.Adjacency{}); nil != err || 0 != len(order) {
+		t.Fatal(order, err)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	g := edges("abcdef", "ca", "cb", "ad", "bd", "ed", "ca", "cd")
+	if levels, err := Levels(g); nil != err || fmt.Sprint(levels) != "[[c e f] [a b] [d]]" {
+		t.Fatal(levels, err)
+	}
+	if levels, err := (Options{Less: reverse}).Levels(g); nil != err || fmt.Sprint(levels) != "[[f e c] [b a] [d]]" {
+		t.Fatal(levels, err)
+	}
+	if levels, err := Levels(&graph.Adjacency{}); nil != err || 0 != len(levels) {
+		t.Fatal(levels, err)
+	}
+}
+
+func TestSort_cycle(t *testing.T) {
+	// a long cycle, b c d e f b, and a shorter one, d e g d, with h reachable from the cycles, and a, i sortable
+	g := edges("abcdefghi", "ab", "bc", "cd", "de", "ef", "fb", "eg", "gd", "gh", "ai")
+	order, err := Sort(g)
+	var cycleErr *CycleError
+	if fmt.Sprint(order) != "[a i]" || false == errors.As(err, &cycleErr) || fmt.Sprint(cycleErr.Cycle) != "[d e g d]" {
+		t.Fatal(order, err)
+	}
+	levels, err := Levels(g)
+	if fmt.Sprint(levels) != "[[a] [i]]" || err.Error() != "cycle detected: d -> e -> g -> d" {
+		t.Fatal(levels, err)
+	}
+	// self-loops are the shortest possible
+	g.AddEdge("h", "h")
+	if _, err := Sort(g); err.Error() != "cycle detected: h -> h" {
+		t.Fatal(err)
+	}
+}
+
+func TestSort_random(t *testing.T) {
+	rand.Seed(1399881)
+	for x := 0; x < 500; x++ {
+		var (
+			g graph.Adjacency
+			n = 1 + rand.Intn(20)
+		)
+		for i := 0; i < n; i++ {
+			g.AddNode(i)
+		}
+		// mostly acyclic
+		for i := rand.Intn(2 * n); i > 0; i-- {
+			a, b := rand.Intn(n), rand.Intn(n)
+			if a > b || 0 == rand.Intn(30) {
+				g.AddEdge(a, b)
+			}
+		}
+		order, err := Sort(&g)
+		levels, levelsErr := Levels(&g)
+		if (nil == err) != (nil == levelsErr) {
+			t.Fatal(x, err, levelsErr)
+		}
+		if nil != err {
+			cycle := err.(*CycleError).Cycle
+			if len(cycle) < 2 || cycle[0] != cycle[len(cycle)-1] {
+				t.Fatal(x, cycle)
+			}
+			for i := 1; i < len(cycle); i++ {
+				if false == contains(g.Successors(cycle[i-1]), cycle[i]) {
+					t.Fatal(x, cycle)
+				}
+			}
+			continue
+		}
+		position := make(map[interface{}]int)
+		for i, v := range order {
+			position[v] = i
+		}
+		level := make(map[interface{}]int)
+		for i, l := range levels {
+			for _, v := range l {
+				level[v] = i
+			}
+		}
+		if n != len(position) || n != len(level) {
+			t.Fatal(x, order, levels)
+		}
+		for _, a := range g.Nodes() {
+			for _, b := range g.Successors(a) {
+				if position[a] >= position[b] || level[a] >= level[b] {
+					t.Fatal(x, a, b)
+				}
+			}
+		}
+	}
+}
+
+func contains(nodes []interface{}, node interface{}) bool {
+	for _, v := range nodes {
+		if v == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package undirected
+
+// OddCycleError models a cycle of odd length, which proves that a graph is not bipartite, and is returned by
+// Bipartition, in place of the colouring.
+type OddCycleError struct {
+	// Cycle contains the nodes that form the cycle, formatted like floyds.BranchingDetector.Cycle, starting and ending
+	// with the same node, e.g. `[a b c a]`, and always has an even number of elements (an odd number of edges).
+	Cycle []interface{}
+}
+
+// Error implements the error interface, describing the cycle, formatting each node using `%v`.
+func (e *OddCycleError) Error() string {
+	return formatCycle("odd cycle detected: ", e.Cycle)
+}
+
+// Bipartition returns a 2-colouring of the undirected graph formed by edges, where no edge is between two nodes of
+// the same colour (false or true), or, if that's not possible, an *OddCycleError, which proves it. The colouring is
+// found using a spanning forest, built using a breadth first search, from each node, in the order they first appear
+// in edges, where the first node of each connected component is coloured false, and every other node is coloured by
+// the parity of it's depth. The cycle is formed by the first edge, in order, between two nodes of the same colour,
+// and the path between them, through the forest, starting and ending with the A of the edge, and is therefore
+// deterministic, though it's not necessarily the shortest. Note that an edge from a node to itself is a cycle of
+// length one, and that nodes that aren't part of any edge can be coloured either way.
+func Bipartition(edges []Edge) (map[interface{}]bool, error) {
+	f := newSpanningForest(edges)
+	for _, e := range edges {
+		if a, b := f.index[e.A], f.index[e.B]; f.depth[a]%2 == f.depth[b]%2 {
+			return nil, &OddCycleError{Cycle: f.cycle(a, b)}
+		}
+	}
+	colouring := make(map[interface{}]bool, len(f.nodes))
+	for i, v := range f.nodes {
+		colouring[v] = 1 == f.depth[i]%2
+	}
+	return colouring, nil
+}
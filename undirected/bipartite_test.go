@@ -0,0 +1,104 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package undirected
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestBipartition(t *testing.T) {
+	for i, tc := range []struct {
+		Edges []Edge
+		Error string
+	}{
+		{nil, ""},
+		{edges("ab", "bc", "cd", "da"), ""},
+		{edges("ab", "ab", "xy"), ""},
+		{edges("ab", "bc", "ca"), "odd cycle detected: b - a - c - b"},
+		{edges("aa"), "odd cycle detected: a - a"},
+		// a square, and a pentagon, sharing the edge ab
+		{edges("ab", "bc", "cd", "da", "be", "ef", "fg", "ga"), "odd cycle detected: e - b - a - g - f - e"},
+	} {
+		colouring, err := Bipartition(tc.Edges)
+		if "" != tc.Error {
+			var cycleErr *OddCycleError
+			if nil == err || err.Error() != tc.Error || false == errors.As(err, &cycleErr) || nil != colouring {
+				t.Error(i, err, colouring)
+			}
+			continue
+		}
+		if nil != err {
+			t.Error(i, err)
+			continue
+		}
+		for _, e := range tc.Edges {
+			if colouring[e.A] == colouring[e.B] {
+				t.Error(i, colouring)
+			}
+		}
+		if 0 != len(tc.Edges) && true == colouring[tc.Edges[0].A] {
+			t.Error(i, colouring)
+		}
+	}
+}
+
+func TestBipartition_random(t *testing.T) {
+	rand.Seed(90123)
+	var odd int
+	for x := 0; x < 500; x++ {
+		var (
+			n     = 1 + rand.Intn(12)
+			edges = make([]Edge, rand.Intn(n+3))
+		)
+		for i := range edges {
+			edges[i] = Edge{rand.Intn(n), rand.Intn(n)}
+		}
+		colouring, err := Bipartition(edges)
+		if nil == err {
+			for _, e := range edges {
+				if colouring[e.A] == colouring[e.B] {
+					t.Fatal(x, edges, colouring)
+				}
+			}
+			continue
+		}
+		odd++
+		// an odd closed walk, via distinct edges
+		cycle := err.(*OddCycleError).Cycle
+		if 0 != len(cycle)%2 || cycle[0] != cycle[len(cycle)-1] {
+			t.Fatal(x, cycle)
+		}
+		used := make(map[int]bool)
+		for i := 1; i < len(cycle); i++ {
+			found := false
+			for j, e := range edges {
+				if false == used[j] && ((e.A == cycle[i-1] && e.B == cycle[i]) || (e.B == cycle[i-1] && e.A == cycle[i])) {
+					used[j], found = true, true
+					break
+				}
+			}
+			if false == found {
+				t.Fatal(x, cycle)
+			}
+		}
+	}
+	if odd < 50 || odd > 450 {
+		t.Fatal(odd)
+	}
+}
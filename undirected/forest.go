@@ -0,0 +1,166 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package undirected
+
+import (
+	"github.com/joeycumines/go-detect-cycle/internal/digraph"
+)
+
+type (
+	// Forest is an undirected graph, that rejects any edge that would create a cycle, meaning it's always a forest
+	// (each connected component is a tree), using union-find (a disjoint set, with path compression, and union by
+	// size), so each edge is checked in amortized near-constant time. The zero value is an empty forest, ready to
+	// use, note that it's not safe to use concurrently, while it's modified.
+	Forest struct {
+		nodes  []interface{}
+		index  map[interface{}]int
+		parent []int
+		size   []int
+		// adjacent contains the accepted edges, which are only used to find the path, for a CycleError
+		adjacent [][]int
+		edges    []Edge
+	}
+
+	// CycleError is returned by Forest.AddEdge, for an edge between two nodes that are already connected, which is
+	// therefore rejected, since the path between them, through the forest, would form a cycle with it.
+	CycleError struct {
+		// Cycle contains the nodes that would form the cycle, formatted like floyds.BranchingDetector.Cycle, starting
+		// and ending with the first node of the edge, followed by the second, e.g. `[a b c a]`, for an edge between
+		// a and b, where b and c, and c and a, are already connected.
+		Cycle []interface{}
+	}
+)
+
+// Error implements the error interface, describing the cycle, formatting each node using `%v`.
+func (e *CycleError) Error() string {
+	return formatCycle("cycle detected: ", e.Cycle)
+}
+
+// The formatCycle function returns prefix, followed by each node of cycle, formatted using `%v`, and joined by
+// dashes, since the edges of an undirected graph have no direction, e.g. `cycle detected: a - b - a`.
+func formatCycle(prefix string, cycle []interface{}) string {
+	return digraph.FormatCycle(prefix, " - ", cycle)
+}
+
+// AddNode adds node to the forest, as a tree of it's own, if it isn't already present.
+func (f *Forest) AddNode(node interface{}) {
+	f.add(node)
+}
+
+// The add method returns the index of node, adding it if it isn't already present.
+func (f *Forest) add(node interface{}) int {
+	if nil == f.index {
+		f.index = make(map[interface{}]int)
+	}
+	if i, ok := f.index[node]; true == ok {
+		return i
+	}
+	i := len(f.nodes)
+	f.nodes = append(f.nodes, node)
+	f.index[node] = i
+	f.parent = append(f.parent, i)
+	f.size = append(f.size, 1)
+	f.adjacent = append(f.adjacent, nil)
+	return i
+}
+
+// AddEdge adds an edge between a and b, adding either node, if it isn't already present, unless they are already
+// connected (including if they are the same node), in which case the edge is not added, and a *CycleError is
+// returned, describing the cycle it would have formed.
+func (f *Forest) AddEdge(a, b interface{}) error {
+	x, y := f.add(a), f.add(b)
+	rx, ry := f.find(x), f.find(y)
+	if rx == ry {
+		path := f.path(y, x)
+		cycle := make([]interface{}, 0, len(path)+1)
+		cycle = append(cycle, a)
+		for _, v := range path {
+			cycle = append(cycle, f.nodes[v])
+		}
+		return &CycleError{Cycle: cycle}
+	}
+	if f.size[rx] < f.size[ry] {
+		rx, ry = ry, rx
+	}
+	f.parent[ry] = rx
+	f.size[rx] += f.size[ry]
+	f.adjacent[x] = append(f.adjacent[x], y)
+	f.adjacent[y] = append(f.adjacent[y], x)
+	f.edges = append(f.edges, Edge{a, b})
+	return nil
+}
+
+// Connected returns true if there is a path between a and b, which is always the case if they are the same node,
+// and never the case if either is not present.
+func (f *Forest) Connected(a, b interface{}) bool {
+	x, ok := f.index[a]
+	if false == ok {
+		return false
+	}
+	y, ok := f.index[b]
+	if false == ok {
+		return false
+	}
+	return f.find(x) == f.find(y)
+}
+
+// Nodes returns every node, in the order they were first added, and must not be modified.
+func (f *Forest) Nodes() []interface{} {
+	return f.nodes
+}
+
+// Edges returns every edge that was accepted by AddEdge, in the order they were added, and must not be modified.
+func (f *Forest) Edges() []Edge {
+	return f.edges
+}
+
+// The find method returns the root of the set containing node, compressing the path.
+func (f *Forest) find(node int) int {
+	root := node
+	for f.parent[root] != root {
+		root = f.parent[root]
+	}
+	for f.parent[node] != root {
+		node, f.parent[node] = f.parent[node], root
+	}
+	return root
+}
+
+// The path method returns the path between two connected nodes, via the accepted edges, inclusive of both, using a
+// breadth first search (since there is only one path, in a tree).
+func (f *Forest) path(from, to int) []int {
+	parent := map[int]int{from: -1}
+	queue := []int{from}
+	for 0 != len(queue) && to != queue[0] {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range f.adjacent[v] {
+			if _, ok := parent[w]; false == ok {
+				parent[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	var path []int
+	for v := to; -1 != v; v = parent[v] {
+		path = append(path, v)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
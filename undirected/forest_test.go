@@ -0,0 +1,87 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package undirected
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestForest_zero(t *testing.T) {
+	var f Forest
+	if nil != f.Nodes() || nil != f.Edges() || f.Connected(1, 1) {
+		t.Fatal(f)
+	}
+}
+
+func TestForest(t *testing.T) {
+	var f Forest
+	f.AddNode("x")
+	for _, e := range [][2]string{{"a", "b"}, {"c", "d"}, {"b", "c"}, {"e", "c"}} {
+		if err := f.AddEdge(e[0], e[1]); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if s := fmt.Sprint(f.Nodes(), f.Edges()); s != "[x a b c d e] [{a b} {c d} {b c} {e c}]" {
+		t.Fatal(s)
+	}
+	if false == f.Connected("a", "e") || false == f.Connected("x", "x") || f.Connected("a", "x") || f.Connected("a", "z") {
+		t.Fatal()
+	}
+	err := f.AddEdge("d", "a")
+	var cycleErr *CycleError
+	if false == errors.As(err, &cycleErr) || err.Error() != "cycle detected: d - a - b - c - d" {
+		t.Fatal(err)
+	}
+	if err := f.AddEdge("b", "a"); nil == err || err.Error() != "cycle detected: b - a - b" {
+		t.Fatal(err)
+	}
+	if err := f.AddEdge("y", "y"); nil == err || err.Error() != "cycle detected: y - y" {
+		t.Fatal(err)
+	}
+	if 4 != len(f.Edges()) || 7 != len(f.Nodes()) {
+		t.Fatal(f.Edges(), f.Nodes())
+	}
+	if err := f.AddEdge("x", "y"); nil != err || false == f.Connected("x", "y") {
+		t.Fatal(err)
+	}
+}
+
+func TestForest_find(t *testing.T) {
+	var f Forest
+	const n = 1000
+	for i := 1; i < n; i++ {
+		if err := f.AddEdge(i-1, i); nil != err {
+			t.Fatal(err)
+		}
+	}
+	// union by size keeps the trees shallow
+	for i := 0; i < n; i++ {
+		var depth int
+		for v := i; f.parent[v] != v; v = f.parent[v] {
+			depth++
+		}
+		if depth > 1 {
+			t.Fatal(i, depth)
+		}
+	}
+	err := f.AddEdge(n-1, 0)
+	if nil == err || n+1 != len(err.(*CycleError).Cycle) {
+		t.Fatal(err)
+	}
+}
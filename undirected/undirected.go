@@ -0,0 +1,147 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package undirected provides cycle detection for undirected graphs, modelled as a list of edges, where the other
+// packages in this module all assume directed edges (a step, or successor). Note that, in an undirected graph, a
+// single edge between two nodes is not a cycle, but two (parallel) edges between the same nodes, or an edge from a
+// node to itself, are. See also Bipartition, which detects cycles of odd length.
+package undirected
+
+// Edge models an undirected edge, between A and B, which must be comparable (they are used as map keys).
+type Edge struct {
+	A, B interface{}
+}
+
+// HasCycle returns true if the undirected graph formed by edges contains a cycle, using a Forest.
+func HasCycle(edges []Edge) bool {
+	var f Forest
+	for _, e := range edges {
+		if nil != f.AddEdge(e.A, e.B) {
+			return true
+		}
+	}
+	return false
+}
+
+// CycleBasis returns a fundamental cycle basis, for the undirected graph formed by edges, which is a set of
+// independent cycles (loops), from which every cycle in the graph can be formed (via the symmetric difference of
+// their edges). It's found using a spanning forest, built using a breadth first search, from each node, in the order
+// they first appear in edges, with one cycle for each edge that is not in the forest, in the order they appear in
+// edges, formed by that edge, and the path between it's nodes, through the forest. Each cycle is formatted like
+// floyds.BranchingDetector.Cycle, starting and ending with the A of the edge, followed by the path to it's B, e.g.
+// `[a b c a]`, for an edge from a to c, where the forest contains the edges between a and b, and b and c. The number
+// of cycles is always the number of edges, minus the number of nodes, plus the number of connected components.
+func CycleBasis(edges []Edge) [][]interface{} {
+	f := newSpanningForest(edges)
+	var basis [][]interface{}
+	for i, e := range edges {
+		if false == f.tree[i] {
+			basis = append(basis, f.cycle(f.index[e.A], f.index[e.B]))
+		}
+	}
+	return basis
+}
+
+// The spanningForest struct models a spanning forest of the undirected graph formed by a list of edges, built using
+// a breadth first search, from each node, in the order they first appear in the edges.
+type spanningForest struct {
+	nodes  []interface{}
+	index  map[interface{}]int
+	parent []int
+	depth  []int
+	// tree indicates if each edge, by index, is part of the forest
+	tree []bool
+}
+
+func newSpanningForest(edges []Edge) spanningForest {
+	type arc struct {
+		node int
+		edge int
+	}
+	var (
+		f        = spanningForest{index: make(map[interface{}]int)}
+		adjacent [][]arc
+	)
+	add := func(node interface{}) int {
+		if i, ok := f.index[node]; true == ok {
+			return i
+		}
+		f.index[node] = len(f.nodes)
+		f.nodes = append(f.nodes, node)
+		adjacent = append(adjacent, nil)
+		return len(f.nodes) - 1
+	}
+	for i, e := range edges {
+		a, b := add(e.A), add(e.B)
+		adjacent[a] = append(adjacent[a], arc{b, i})
+		if a != b {
+			adjacent[b] = append(adjacent[b], arc{a, i})
+		}
+	}
+
+	f.parent = make([]int, len(f.nodes))
+	f.depth = make([]int, len(f.nodes))
+	f.tree = make([]bool, len(edges))
+	visited := make([]bool, len(f.nodes))
+	for root := range f.nodes {
+		if true == visited[root] {
+			continue
+		}
+		visited[root] = true
+		f.parent[root] = -1
+		queue := []int{root}
+		for 0 != len(queue) {
+			v := queue[0]
+			queue = queue[1:]
+			for _, a := range adjacent[v] {
+				if false == visited[a.node] {
+					visited[a.node] = true
+					f.parent[a.node] = v
+					f.depth[a.node] = f.depth[v] + 1
+					f.tree[a.edge] = true
+					queue = append(queue, a.node)
+				}
+			}
+		}
+	}
+	return f
+}
+
+// The cycle method returns the cycle formed by an edge between a and b (which must be in the same tree), and the
+// path between them, through the forest, via their lowest common ancestor, starting and ending with a.
+func (f spanningForest) cycle(a, b int) []interface{} {
+	var (
+		up   = []int{a}
+		down []int
+	)
+	for a != b {
+		if f.depth[a] >= f.depth[b] {
+			a = f.parent[a]
+			up = append(up, a)
+		} else {
+			down = append(down, b)
+			b = f.parent[b]
+		}
+	}
+	cycle := make([]interface{}, 0, len(up)+len(down)+1)
+	for _, v := range up {
+		cycle = append(cycle, f.nodes[v])
+	}
+	for i := len(down) - 1; i >= 0; i-- {
+		cycle = append(cycle, f.nodes[down[i]])
+	}
+	return append(cycle, cycle[0])
+}
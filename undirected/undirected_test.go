@@ -0,0 +1,121 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package undirected
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// The edges function returns edges, of the form "ab", for an edge between a and b.
+func edges(edges ...string) []Edge {
+	result := make([]Edge, len(edges))
+	for i, e := range edges {
+		result[i] = Edge{e[:1], e[1:]}
+	}
+	return result
+}
+
+func TestHasCycle(t *testing.T) {
+	for i, tc := range []struct {
+		Edges    []Edge
+		Expected bool
+	}{
+		{nil, false},
+		{edges("ab", "bc", "cd", "ce"), false},
+		{edges("ab", "bc", "ca"), true},
+		{edges("ab", "ba"), true},
+		{edges("ab", "cc"), true},
+		{edges("ab", "cd", "ef", "ac", "de"), false},
+		{edges("ab", "cd", "ef", "ac", "de", "eb"), true},
+	} {
+		if actual := HasCycle(tc.Edges); actual != tc.Expected {
+			t.Error(i, actual)
+		}
+	}
+}
+
+func TestCycleBasis(t *testing.T) {
+	for i, tc := range []struct {
+		Edges    []Edge
+		Expected string
+	}{
+		{nil, "[]"},
+		{edges("ab", "bc", "cd"), "[]"},
+		{edges("ab", "bc", "ca"), "[[b a c b]]"},
+		{edges("ab", "ab", "aa"), "[[a b a] [a a]]"},
+		// two squares, sharing the edge be, with a tail
+		{edges("ab", "bc", "cf", "fe", "ed", "da", "be", "fg"), "[[f c b e f] [e b a d e]]"},
+		// two triangles, in different components
+		{edges("ab", "bc", "ca", "xy", "yz", "zx"), "[[b a c b] [y x z y]]"},
+	} {
+		if actual := fmt.Sprint(CycleBasis(tc.Edges)); actual != tc.Expected {
+			t.Error(i, actual)
+		}
+	}
+}
+
+func TestCycleBasis_random(t *testing.T) {
+	rand.Seed(300012)
+	for x := 0; x < 300; x++ {
+		var (
+			n     = 1 + rand.Intn(15)
+			edges = make([]Edge, rand.Intn(2*n))
+		)
+		for i := range edges {
+			edges[i] = Edge{rand.Intn(n), rand.Intn(n)}
+		}
+		var (
+			f          Forest
+			components = make(map[interface{}]struct{})
+		)
+		for _, e := range edges {
+			_ = f.AddEdge(e.A, e.B)
+		}
+		for _, v := range f.Nodes() {
+			components[f.find(f.index[v])] = struct{}{}
+		}
+		basis := CycleBasis(edges)
+		if expected := len(edges) - len(f.Nodes()) + len(components); len(basis) != expected {
+			t.Fatal(x, len(basis), expected)
+		}
+		if HasCycle(edges) != (0 != len(basis)) {
+			t.Fatal(x)
+		}
+		// every cycle is a closed walk, via distinct edges
+		for _, cycle := range basis {
+			used := make(map[int]bool)
+			if cycle[0] != cycle[len(cycle)-1] {
+				t.Fatal(x, cycle)
+			}
+			for i := 1; i < len(cycle); i++ {
+				found := false
+				for j, e := range edges {
+					if false == used[j] && ((e.A == cycle[i-1] && e.B == cycle[i]) || (e.B == cycle[i-1] && e.A == cycle[i])) {
+						used[j] = true
+						found = true
+						break
+					}
+				}
+				if false == found {
+					t.Fatal(x, cycle)
+				}
+			}
+		}
+	}
+}
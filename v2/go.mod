@@ -0,0 +1,3 @@
+module github.com/joeycumines/go-detect-cycle/v2
+
+go 1.18
@@ -0,0 +1,198 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package weighted
+
+type (
+	// The karpTable interface models the table used by Karp's algorithm, independently of the weight type, where
+	// D(k, v) is the minimum weight of any walk of exactly k edges, ending at v (from any node, where D(0, v) = 0).
+	karpTable interface {
+		// relax sets D(k, to) via the edge e, from D(k-1, from), if it's smaller, or unset, returning true if it was set
+		relax(k, e int) bool
+		// reachable returns true if D(k, v) is set
+		reachable(k, v int) bool
+		// lessMean returns true if (D(n, a) - D(ka, a)) / (n - ka) < (D(n, b) - D(kb, b)) / (n - kb)
+		lessMean(a, ka, b, kb int) bool
+	}
+
+	// The floatTable struct implements karpTable, for float64 weights.
+	floatTable struct {
+		x     index
+		edges []Edge
+		d     [][]float64
+		set   [][]bool
+	}
+
+	// The intTable struct implements karpTable, for int64 weights, comparing means exactly.
+	intTable struct {
+		x     index
+		edges []IntEdge
+		d     [][]int64
+		set   [][]bool
+	}
+)
+
+// MinimumMeanCycle returns a cycle with the minimum mean weight (the total weight, divided by the number of edges),
+// as the edges that form it, in order, along with it's mean weight, and true, or false if the graph is acyclic. It
+// uses Karp's algorithm, which takes O(ne) time, and O(n^2) memory, where n is the number of nodes, and e is the
+// number of edges, with the cycle found by following the minimum weight walk of n edges, to the node that achieves
+// the minimum, which always contains a minimum mean cycle. Note that, since float64 arithmetic is inexact, the cycle
+// may not be the minimum, if there are multiple cycles with very similar means, see also MinimumMeanCycleInt.
+func MinimumMeanCycle(edges []Edge) ([]Edge, float64, bool) {
+	x := newIndex(len(edges), func(e int) (interface{}, interface{}) { return edges[e].From, edges[e].To })
+	t := &floatTable{x: x, edges: edges}
+	t.d, t.set = make([][]float64, len(x.nodes)+1), make([][]bool, len(x.nodes)+1)
+	for k := range t.d {
+		t.d[k], t.set[k] = make([]float64, len(x.nodes)), make([]bool, len(x.nodes))
+	}
+	for v := range t.set[0] {
+		t.set[0][v] = true
+	}
+	cycle := x.minimumMeanCycle(t)
+	if nil == cycle {
+		return nil, 0, false
+	}
+	result := make([]Edge, len(cycle))
+	var weight float64
+	for i, e := range cycle {
+		result[i] = edges[e]
+		weight += edges[e].Weight
+	}
+	return result, weight / float64(len(cycle)), true
+}
+
+// MinimumMeanCycleInt is MinimumMeanCycle, for int64 weights, which compares means exactly, returning the mean
+// weight as it's numerator (the total weight of the cycle) and denominator (the number of edges, which is also the
+// length of the cycle), note that the weights, multiplied by the square of the number of nodes, must not overflow.
+func MinimumMeanCycleInt(edges []IntEdge) ([]IntEdge, int64, bool) {
+	x := newIndex(len(edges), func(e int) (interface{}, interface{}) { return edges[e].From, edges[e].To })
+	t := &intTable{x: x, edges: edges}
+	t.d, t.set = make([][]int64, len(x.nodes)+1), make([][]bool, len(x.nodes)+1)
+	for k := range t.d {
+		t.d[k], t.set[k] = make([]int64, len(x.nodes)), make([]bool, len(x.nodes))
+	}
+	for v := range t.set[0] {
+		t.set[0][v] = true
+	}
+	cycle := x.minimumMeanCycle(t)
+	if nil == cycle {
+		return nil, 0, false
+	}
+	result := make([]IntEdge, len(cycle))
+	var weight int64
+	for i, e := range cycle {
+		result[i] = edges[e]
+		weight += edges[e].Weight
+	}
+	return result, weight, true
+}
+
+// The minimumMeanCycle method implements Karp's algorithm, independently of the weight type, returning the indexes
+// of the edges that form a minimum mean cycle, in order, or nil if there are no cycles.
+func (x index) minimumMeanCycle(t karpTable) []int {
+	n := len(x.nodes)
+	// parent contains the edge, of the minimum weight walk of k edges, to each node
+	parent := make([][]int, n+1)
+	for k := range parent {
+		parent[k] = make([]int, n)
+	}
+	for k := 1; k <= n; k++ {
+		for e := range x.from {
+			if t.reachable(k-1, x.from[e]) && t.relax(k, e) {
+				parent[k][x.to[e]] = e
+			}
+		}
+	}
+
+	// the minimum, over every node, of the maximum, over every k, of (D(n, v) - D(k, v)) / (n - k)
+	best, bestK := -1, -1
+	for v := 0; v < n; v++ {
+		if false == t.reachable(n, v) {
+			continue
+		}
+		worst := -1
+		for k := 0; k < n; k++ {
+			if t.reachable(k, v) && (-1 == worst || t.lessMean(v, worst, v, k)) {
+				worst = k
+			}
+		}
+		if -1 == best || t.lessMean(v, worst, best, bestK) {
+			best, bestK = v, worst
+		}
+	}
+	if -1 == best {
+		return nil
+	}
+
+	// follow the walk of n edges, backwards, until a node repeats
+	var (
+		seen = make(map[int]int) // node to the k it was seen at
+		v    = best
+	)
+	for k := n; ; k-- {
+		if j, ok := seen[v]; true == ok {
+			// the edges of the walk, between k and j, form the cycle, which are collected in reverse
+			cycle := make([]int, 0, j-k)
+			for u, i := v, j; i > k; i-- {
+				e := parent[i][u]
+				cycle = append(cycle, e)
+				u = x.from[e]
+			}
+			for a, b := 0, len(cycle)-1; a < b; a, b = a+1, b-1 {
+				cycle[a], cycle[b] = cycle[b], cycle[a]
+			}
+			return cycle
+		}
+		seen[v] = k
+		v = x.from[parent[k][v]]
+	}
+}
+
+func (t *floatTable) relax(k, e int) bool {
+	from, to := t.x.from[e], t.x.to[e]
+	if d := t.d[k-1][from] + t.edges[e].Weight; false == t.set[k][to] || d < t.d[k][to] {
+		t.d[k][to], t.set[k][to] = d, true
+		return true
+	}
+	return false
+}
+
+func (t *floatTable) reachable(k, v int) bool {
+	return t.set[k][v]
+}
+
+func (t *floatTable) lessMean(a, ka, b, kb int) bool {
+	n := len(t.d) - 1
+	return (t.d[n][a]-t.d[ka][a])/float64(n-ka) < (t.d[n][b]-t.d[kb][b])/float64(n-kb)
+}
+
+func (t *intTable) relax(k, e int) bool {
+	from, to := t.x.from[e], t.x.to[e]
+	if d := t.d[k-1][from] + t.edges[e].Weight; false == t.set[k][to] || d < t.d[k][to] {
+		t.d[k][to], t.set[k][to] = d, true
+		return true
+	}
+	return false
+}
+
+func (t *intTable) reachable(k, v int) bool {
+	return t.set[k][v]
+}
+
+func (t *intTable) lessMean(a, ka, b, kb int) bool {
+	n := len(t.d) - 1
+	return (t.d[n][a]-t.d[ka][a])*int64(n-kb) < (t.d[n][b]-t.d[kb][b])*int64(n-ka)
+}
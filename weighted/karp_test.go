@@ -0,0 +1,113 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package weighted
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/joeycumines/go-detect-cycle/graph"
+	"github.com/joeycumines/go-detect-cycle/johnson"
+)
+
+func TestMinimumMeanCycle(t *testing.T) {
+	for i, tc := range []struct {
+		Edges []Edge
+		Cycle string
+		Mean  float64
+	}{
+		{nil, "[]", 0},
+		{[]Edge{{1, 2, 1}, {2, 3, 1}}, "[]", 0},
+		{[]Edge{{1, 2, 1}, {2, 1, 2}}, "[{1 2 1} {2 1 2}]", 1.5},
+		{[]Edge{{1, 2, 1}, {2, 1, 2}, {2, 2, 1.25}}, "[{2 2 1.25}]", 1.25},
+		// a long cycle, with a lower mean than the short one
+		{[]Edge{{1, 2, 2}, {2, 1, 2}, {2, 3, 1}, {3, 4, 1}, {4, 5, 1}, {5, 2, 1}}, "[{2 3 1} {3 4 1} {4 5 1} {5 2 1}]", 1},
+		{[]Edge{{1, 2, -1}, {2, 1, -2}, {3, 3, 0}}, "[{1 2 -1} {2 1 -2}]", -1.5},
+	} {
+		cycle, mean, ok := MinimumMeanCycle(tc.Edges)
+		if s := fmt.Sprint(cycle); s != tc.Cycle || mean != tc.Mean || ok != (0 != len(cycle)) {
+			t.Error(i, s, mean, ok)
+		}
+	}
+}
+
+func TestMinimumMeanCycleInt(t *testing.T) {
+	cycle, weight, ok := MinimumMeanCycleInt([]IntEdge{{"a", "b", 3}, {"b", "c", -1}, {"c", "a", 0}, {"b", "a", 2}})
+	if false == ok || 2 != weight || 3 != len(cycle) || false == validCycle(cycle) {
+		t.Fatal(cycle, weight, ok)
+	}
+	if cycle, weight, ok := MinimumMeanCycleInt([]IntEdge{{"a", "b", 3}}); true == ok {
+		t.Fatal(cycle, weight)
+	}
+}
+
+func TestMinimumMeanCycleInt_random(t *testing.T) {
+	rand.Seed(662191)
+	for x := 0; x < 500; x++ {
+		n := 1 + rand.Intn(8)
+		edges := randomIntEdges(rand.Intn(3*n), n, -5, 10)
+		// the minimum mean, of every circuit, via johnson, with parallel edges replaced by the minimum
+		var (
+			g       graph.Adjacency
+			minimum = make(map[[2]interface{}]int64)
+		)
+		for _, e := range edges {
+			key := [2]interface{}{e.From, e.To}
+			if w, ok := minimum[key]; false == ok || e.Weight < w {
+				minimum[key] = e.Weight
+			}
+			g.AddEdge(e.From, e.To)
+		}
+		var expected *big.Rat
+		_ = johnson.Circuits(&g, func(cycle []interface{}) error {
+			var sum int64
+			for i := 1; i < len(cycle); i++ {
+				sum += minimum[[2]interface{}{cycle[i-1], cycle[i]}]
+			}
+			if mean := big.NewRat(sum, int64(len(cycle)-1)); nil == expected || mean.Cmp(expected) < 0 {
+				expected = mean
+			}
+			return nil
+		})
+		cycle, weight, ok := MinimumMeanCycleInt(edges)
+		if ok != (nil != expected) {
+			t.Fatal(x, edges, cycle)
+		}
+		if false == ok {
+			continue
+		}
+		var sum int64
+		for _, e := range cycle {
+			sum += e.Weight
+		}
+		if false == validCycle(cycle) || sum != weight || 0 != big.NewRat(weight, int64(len(cycle))).Cmp(expected) {
+			t.Fatal(x, edges, cycle, weight, expected)
+		}
+		// and the float variant agrees
+		floats := make([]Edge, len(edges))
+		for i, e := range edges {
+			floats[i] = Edge{e.From, e.To, float64(e.Weight)}
+		}
+		expectedFloat, _ := expected.Float64()
+		if _, mean, ok := MinimumMeanCycle(floats); false == ok || math.Abs(mean-expectedFloat) > 1e-9 {
+			t.Fatal(x, mean, expectedFloat)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package weighted
+
+// NegativeCycle returns a cycle with a negative total weight, as the edges that form it, in order, along with it's
+// total weight, and true, or false if there are no negative cycles. It uses the Bellman-Ford algorithm, in the form
+// of SPFA (the queue based variant), from a virtual source, with an edge to every node, meaning negative cycles are
+// found anywhere in the graph. Rather than counting iterations, the predecessor graph is periodically checked for a
+// cycle, which, if present, is always negative, meaning a cycle is typically detected soon after it's first relaxed.
+// Note that, since float64 arithmetic is inexact, cycles with a total weight very close to zero may or may not be
+// detected, and any edges with a NaN weight are effectively ignored.
+func NegativeCycle(edges []Edge) ([]Edge, float64, bool) {
+	x := newIndex(len(edges), func(e int) (interface{}, interface{}) { return edges[e].From, edges[e].To })
+	dist := make([]float64, len(x.nodes))
+	cycle := x.negativeCycle(func(e int) bool {
+		if d := dist[x.from[e]] + edges[e].Weight; d < dist[x.to[e]] {
+			dist[x.to[e]] = d
+			return true
+		}
+		return false
+	})
+	if nil == cycle {
+		return nil, 0, false
+	}
+	result := make([]Edge, len(cycle))
+	var weight float64
+	for i, e := range cycle {
+		result[i] = edges[e]
+		weight += edges[e].Weight
+	}
+	return result, weight, true
+}
+
+// NegativeCycleInt is NegativeCycle, for int64 weights, which does not suffer from the inexactness of float64, note
+// that the (intermediate) distances must not overflow, which could only happen with extremely large weights.
+func NegativeCycleInt(edges []IntEdge) ([]IntEdge, int64, bool) {
+	x := newIndex(len(edges), func(e int) (interface{}, interface{}) { return edges[e].From, edges[e].To })
+	dist := make([]int64, len(x.nodes))
+	cycle := x.negativeCycle(func(e int) bool {
+		if d := dist[x.from[e]] + edges[e].Weight; d < dist[x.to[e]] {
+			dist[x.to[e]] = d
+			return true
+		}
+		return false
+	})
+	if nil == cycle {
+		return nil, 0, false
+	}
+	result := make([]IntEdge, len(cycle))
+	var weight int64
+	for i, e := range cycle {
+		result[i] = edges[e]
+		weight += edges[e].Weight
+	}
+	return result, weight, true
+}
+
+// The negativeCycle method implements SPFA, independently of the weight type, where relax attempts to relax the
+// distance of the node at the end of an edge, returning true if it was reduced, returning the indexes of the edges
+// that form a negative cycle, or nil.
+func (x index) negativeCycle(relax func(e int) bool) []int {
+	var (
+		n       = len(x.nodes)
+		parent  = make([]int, n) // the edge that last relaxed each node
+		queued  = make([]bool, n)
+		queue   = make([]int, 0, n)
+		relaxed int
+	)
+	for v := range parent {
+		parent[v] = -1
+		queued[v] = true
+		queue = append(queue, v)
+	}
+	for 0 != len(queue) {
+		u := queue[0]
+		queue = queue[1:]
+		queued[u] = false
+		for _, e := range x.out[u] {
+			if false == relax(e) {
+				continue
+			}
+			v := x.to[e]
+			parent[v] = e
+			relaxed++
+			if 0 == relaxed%n {
+				if cycle := x.parentCycle(parent); nil != cycle {
+					return cycle
+				}
+			}
+			if false == queued[v] {
+				queued[v] = true
+				queue = append(queue, v)
+			}
+		}
+	}
+	return nil
+}
+
+// The parentCycle method returns the indexes of the edges that form a cycle in the predecessor graph, described by
+// parent (which contains the edge to each node, or -1), in order, or nil if there is none.
+func (x index) parentCycle(parent []int) []int {
+	walk := make([]int, len(parent)) // the (one-based) start of the walk that visited each node
+	for start := range parent {
+		if 0 != walk[start] {
+			continue
+		}
+		v := start
+		for 0 == walk[v] {
+			walk[v] = start + 1
+			if -1 == parent[v] {
+				break
+			}
+			v = x.from[parent[v]]
+		}
+		if start+1 != walk[v] || -1 == parent[v] {
+			continue
+		}
+		// v is on a cycle, found during this walk
+		var cycle []int
+		for u := v; ; {
+			e := parent[u]
+			cycle = append(cycle, e)
+			if u = x.from[e]; u == v {
+				break
+			}
+		}
+		for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+			cycle[i], cycle[j] = cycle[j], cycle[i]
+		}
+		return cycle
+	}
+	return nil
+}
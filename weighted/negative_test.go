@@ -0,0 +1,145 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package weighted
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNegativeCycle_arbitrage(t *testing.T) {
+	// exchange rates, where converting USD -> EUR -> GBP -> USD yields a profit
+	rates := []struct {
+		From, To string
+		Rate     float64
+	}{
+		{"USD", "EUR", 0.9},
+		{"EUR", "USD", 1.1},
+		{"EUR", "GBP", 0.8},
+		{"GBP", "USD", 1.4},
+		{"USD", "JPY", 150},
+		{"JPY", "USD", 0.0066},
+	}
+	edges := make([]Edge, len(rates))
+	for i, r := range rates {
+		edges[i] = Edge{r.From, r.To, -math.Log(r.Rate)}
+	}
+	cycle, weight, ok := NegativeCycle(edges)
+	if false == ok || 3 != len(cycle) || math.Abs(math.Exp(-weight)-0.9*0.8*1.4) > 1e-9 {
+		t.Fatal(cycle, weight, ok)
+	}
+	if s := fmt.Sprint([]interface{}{cycle[0].From, cycle[1].From, cycle[2].From}); s != "[USD EUR GBP]" {
+		t.Fatal(s)
+	}
+	// without the profitable rate
+	edges[3].Weight = -math.Log(1.3)
+	if cycle, weight, ok := NegativeCycle(edges); true == ok {
+		t.Fatal(cycle, weight)
+	}
+}
+
+func TestNegativeCycle(t *testing.T) {
+	for i, tc := range []struct {
+		Edges  []Edge
+		Cycle  string
+		Weight float64
+	}{
+		{nil, "[]", 0},
+		{[]Edge{{1, 2, -1}, {2, 3, -1}}, "[]", 0},
+		{[]Edge{{1, 2, -1}, {2, 1, 1}}, "[]", 0},
+		{[]Edge{{1, 2, -1}, {2, 1, 0.5}}, "[{1 2 -1} {2 1 0.5}]", -0.5},
+		{[]Edge{{1, 1, 1}, {2, 2, -1}}, "[{2 2 -1}]", -1},
+		{[]Edge{{1, 2, 1}, {2, 3, math.NaN()}, {3, 1, -5}}, "[]", 0},
+		{[]Edge{{0, 1, 5}, {1, 2, -2}, {2, 3, 1}, {3, 1, -1}, {3, 4, 1}}, "[{1 2 -2} {2 3 1} {3 1 -1}]", -2},
+	} {
+		cycle, weight, ok := NegativeCycle(tc.Edges)
+		if s := fmt.Sprint(cycle); s != tc.Cycle || weight != tc.Weight || ok != (0 != len(cycle)) {
+			t.Error(i, s, weight, ok)
+		}
+	}
+}
+
+func TestNegativeCycleInt(t *testing.T) {
+	cycle, weight, ok := NegativeCycleInt([]IntEdge{{"a", "b", 3}, {"b", "c", -2}, {"c", "a", -2}, {"c", "d", 1}})
+	if false == ok || -1 != weight || false == validCycle(cycle) || 3 != len(cycle) {
+		t.Fatal(cycle, weight, ok)
+	}
+	if cycle, weight, ok := NegativeCycleInt([]IntEdge{{"a", "b", 3}, {"b", "a", -3}}); true == ok {
+		t.Fatal(cycle, weight)
+	}
+}
+
+// The hasNegativeCycle function uses the Floyd-Warshall algorithm, to check for a negative cycle.
+func hasNegativeCycle(edges []IntEdge, n int) bool {
+	const inf = math.MaxInt64 / 4
+	dist := make([][]int64, n)
+	for i := range dist {
+		dist[i] = make([]int64, n)
+		for j := range dist[i] {
+			dist[i][j] = inf
+		}
+	}
+	for _, e := range edges {
+		if a, b := e.From.(int), e.To.(int); e.Weight < dist[a][b] {
+			dist[a][b] = e.Weight
+		}
+	}
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if dist[i][k] < inf && dist[k][j] < inf && dist[i][k]+dist[k][j] < dist[i][j] {
+					dist[i][j] = dist[i][k] + dist[k][j]
+				}
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		if dist[i][i] < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNegativeCycleInt_random(t *testing.T) {
+	rand.Seed(5512321)
+	var found int
+	for x := 0; x < 1000; x++ {
+		n := 1 + rand.Intn(12)
+		edges := randomIntEdges(rand.Intn(3*n), n, -3, 10)
+		cycle, weight, ok := NegativeCycleInt(edges)
+		if ok != hasNegativeCycle(edges, n) {
+			t.Fatal(x, edges, cycle)
+		}
+		if false == ok {
+			continue
+		}
+		found++
+		var sum int64
+		for _, e := range cycle {
+			sum += e.Weight
+		}
+		if false == validCycle(cycle) || weight != sum || weight >= 0 {
+			t.Fatal(x, cycle, weight)
+		}
+	}
+	if found < 100 {
+		t.Fatal(found)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package weighted provides cycle analysis for weighted directed graphs, modelled as a list of edges, with variants
+// for both float64 (Edge) and int64 (IntEdge) weights, where the nodes must be comparable (they are used as map keys).
+// Nodes are identified in the order they first appear in the edges, and edges are always processed in order, meaning
+// the results are deterministic.
+package weighted
+
+type (
+	// Edge models a directed edge, from one node to another, with a float64 weight.
+	Edge struct {
+		From, To interface{}
+		Weight   float64
+	}
+
+	// IntEdge models a directed edge, from one node to another, with an int64 weight.
+	IntEdge struct {
+		From, To interface{}
+		Weight   int64
+	}
+
+	// The index struct is an indexed representation of the edges, shared by the algorithms, for both weight types.
+	index struct {
+		nodes []interface{}
+		from  []int
+		to    []int
+		// out contains the index of each edge from each node
+		out [][]int
+	}
+)
+
+// The newIndex function returns the index for n edges, using endpoints to get the nodes of each edge.
+func newIndex(n int, endpoints func(edge int) (from, to interface{})) index {
+	var (
+		x = index{
+			from: make([]int, n),
+			to:   make([]int, n),
+		}
+		nodes = make(map[interface{}]int)
+	)
+	add := func(node interface{}) int {
+		if i, ok := nodes[node]; true == ok {
+			return i
+		}
+		nodes[node] = len(x.nodes)
+		x.nodes = append(x.nodes, node)
+		x.out = append(x.out, nil)
+		return len(x.nodes) - 1
+	}
+	for e := 0; e < n; e++ {
+		from, to := endpoints(e)
+		x.from[e], x.to[e] = add(from), add(to)
+		x.out[x.from[e]] = append(x.out[x.from[e]], e)
+	}
+	return x
+}
@@ -0,0 +1,53 @@
+/*
+   Copyright 2020 Joseph Cumines
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package weighted
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// The randomIntEdges function generates n random edges, between m nodes, with weights in [min, max].
+func randomIntEdges(n, m int, min, max int64) []IntEdge {
+	edges := make([]IntEdge, n)
+	for i := range edges {
+		edges[i] = IntEdge{rand.Intn(m), rand.Intn(m), min + rand.Int63n(max-min+1)}
+	}
+	return edges
+}
+
+// The validCycle function returns true if edges form a closed walk, in order.
+func validCycle(edges []IntEdge) bool {
+	if 0 == len(edges) {
+		return false
+	}
+	for i, e := range edges {
+		if e.To != edges[(i+1)%len(edges)].From {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNewIndex(t *testing.T) {
+	edges := []IntEdge{{"a", "b", 1}, {"c", "a", 2}, {"a", "c", 3}, {"d", "d", 4}}
+	x := newIndex(len(edges), func(e int) (interface{}, interface{}) { return edges[e].From, edges[e].To })
+	if s := fmt.Sprint(x.nodes, x.from, x.to, x.out); s != "[a b c d] [0 2 0 3] [1 0 2 3] [[0 2] [] [1] [3]]" {
+		t.Fatal(s)
+	}
+}